@@ -5,22 +5,33 @@ import (
 	"fmt"
 	"io/fs"
 	"log/slog"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 )
 
 type Repo struct {
-	Name             string    `json:"name"`
-	Path             string    `json:"-"`
-	AbsPath          string    `json:"path"`
-	LastModified     time.Time `json:"lastModified"`
-	SyncedWithRemote bool      `json:"synced"`
-	SyncDetails      []string  `json:"syncDetails"`
-	Author           string    `json:"author"`
+	Name             string    `json:"name" doc:"name of the repo directory"`
+	Path             string    `json:"relPath" doc:"path of the repo relative to the directory that was searched"`
+	AbsPath          string    `json:"path" doc:"absolute path of the repo"`
+	LastModified     time.Time `json:"lastModified" doc:"last time a file in the repo was modified"`
+	SyncedWithRemote bool      `json:"synced" doc:"whether the repo has no changes that are not on the remote"`
+	SyncDetails      []string  `json:"syncDetails" doc:"reasons the repo is not synced"`
+	Author           string    `json:"author" doc:"author of the last commit"`
+	AuthorEmail      string    `json:"authorEmail" doc:"email of the author of the last commit"`
+	Size             int64     `json:"size" doc:"size of the files in the repo in bytes"`
+	Branch           string    `json:"branch" doc:"current branch, empty when detached"`
+	Ahead            int       `json:"ahead" doc:"commits on the current branch that are not on its upstream"`
+	Behind           int       `json:"behind" doc:"commits on the upstream that are not on the current branch"`
+	Remote           string    `json:"remote" doc:"url of the origin remote or the first remote"`
+	UncommittedFiles int       `json:"uncommittedFiles" doc:"number of files with uncommitted changes including untracked files"`
+	FetchFailed      bool      `json:"fetchFailed" doc:"whether git fetch failed so that the sync status may be out of date"`
 }
 
 // recursively traverses all paths in 'root' and returns a slice of local git Repos
@@ -28,7 +39,16 @@ type Repo struct {
 // the fetch argument determines if a git fetch is ran for each repo before
 // getting rest of the repo details
 func GetReposWithDetails(root string, fetch bool) ([]Repo, error) {
+	return GetReposWithDetailsFunc(root, fetch, nil)
+}
+
+// same as GetReposWithDetails but also calls onRepo with each repo as soon as
+// its details have been gathered so that repos can be used before all repos
+// are done. onRepo is never called concurrently and can be nil
+func GetReposWithDetailsFunc(root string, fetch bool, onRepo func(Repo)) ([]Repo, error) {
 	var wg sync.WaitGroup
+	// serializes the calls to onRepo
+	var mu sync.Mutex
 	fsys := os.DirFS(root)
 	// gather all the repo paths first so that each repo can be concurrently
 	// processed below
@@ -44,49 +64,18 @@ func GetReposWithDetails(root string, fetch bool) ([]Repo, error) {
 		wg.Add(1)
 		go func(i int, path string) {
 			defer wg.Done()
-			absPath := filepath.Join(root, path)
-			dirFS, err := fs.Sub(fsys, path)
+			repo, err := GetRepoDetails(root, path, fetch)
 			if err != nil {
-				slog.Warn(fmt.Sprintf("Unable to get the filesystem at %v, %v", absPath, err))
+				// skip this directory as it is most likely not a valid git repo
+				slog.Warn(err.Error())
 				return
 			}
-			if fetch {
-				err = gitFetch(absPath)
-				if err != nil {
-					// continue without returning because git fetch can fail due to
-					// network issues and the rest of the repo details can likely be
-					// gathered
-					slog.Warn(fmt.Sprintf("Unable to run git fetch at %v, %v", absPath, err))
-				}
-			}
-			lastModified, err := getContentLastModifiedTime(dirFS)
-			// continue without returning if lastmodified date could
-			// not be calculated as it might still be possible for the the
-			// directory to be a valid git repo
-			if err != nil {
-				slog.Warn(fmt.Sprintf("Unable get last modified time in %v, %v", absPath, err))
+			repos[i] = repo
+			if onRepo != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				onRepo(repo)
 			}
-			syncedWithRemote, syncDescription, err := getSyncStatus(absPath)
-			if err != nil {
-				// skip this directory as it is most likely not a valid git repo if git status and
-				// git for-each-ref cannot be run
-				slog.Warn(fmt.Sprintf("Unable to run git commands in %v, %v", absPath, err))
-				return
-			}
-			author, err := getLastCommitAuthor(absPath)
-			if err != nil {
-				slog.Warn(fmt.Sprintf("Unable to get commit author in %v, %v", absPath, err))
-			}
-			repos[i] = Repo{
-				Name:             filepath.Base(path),
-				Path:             path,
-				AbsPath:          absPath,
-				LastModified:     lastModified,
-				SyncedWithRemote: syncedWithRemote,
-				SyncDetails:      syncDescription,
-				Author:           author,
-			}
-
 		}(i, path)
 	}
 	wg.Wait()
@@ -101,6 +90,73 @@ func GetReposWithDetails(root string, fetch bool) ([]Repo, error) {
 	return validRepos, nil
 }
 
+// returns the details of the repo at path relative to root. Details that
+// cannot be gathered are logged as warnings and left empty. An error is
+// returned only if the directory is most likely not a valid git repo
+func GetRepoDetails(root string, path string, fetch bool) (Repo, error) {
+	absPath := filepath.Join(root, path)
+	dirFS, err := fs.Sub(os.DirFS(root), path)
+	if err != nil {
+		return Repo{}, fmt.Errorf("Unable to get the filesystem at %v, %v", absPath, err)
+	}
+	var fetchFailed bool
+	if fetch {
+		err = gitFetch(absPath)
+		if err != nil {
+			fetchFailed = true
+			// continue without returning because git fetch can fail due to
+			// network issues and the rest of the repo details can likely be
+			// gathered
+			slog.Warn(fmt.Sprintf("Unable to run git fetch at %v, %v", absPath, err))
+		}
+	}
+	lastModified, size, err := getContentLastModifiedTimeAndSize(dirFS)
+	// continue without returning if lastmodified date could
+	// not be calculated as it might still be possible for the the
+	// directory to be a valid git repo
+	if err != nil {
+		slog.Warn(fmt.Sprintf("Unable get last modified time and size in %v, %v", absPath, err))
+	}
+	syncedWithRemote, syncDescription, uncommittedFiles, err := getSyncStatus(absPath)
+	if err != nil {
+		// git status and git for-each-ref cannot be run
+		return Repo{}, fmt.Errorf("Unable to run git commands in %v, %v", absPath, err)
+	}
+	author, authorEmail, err := getLastCommitAuthor(absPath)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("Unable to get commit author in %v, %v", absPath, err))
+	}
+	branch, err := getCurrentBranch(absPath)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("Unable to get current branch in %v, %v", absPath, err))
+	}
+	ahead, behind, err := getAheadBehind(absPath, branch)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("Unable to get commits ahead and behind in %v, %v", absPath, err))
+	}
+	remote, err := getRemoteURL(absPath)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("Unable to get remote in %v, %v", absPath, err))
+	}
+	return Repo{
+		Name:             filepath.Base(path),
+		Path:             path,
+		AbsPath:          absPath,
+		LastModified:     lastModified,
+		SyncedWithRemote: syncedWithRemote,
+		SyncDetails:      syncDescription,
+		Author:           author,
+		AuthorEmail:      authorEmail,
+		Size:             size,
+		Branch:           branch,
+		Ahead:            ahead,
+		Behind:           behind,
+		Remote:           remote,
+		UncommittedFiles: uncommittedFiles,
+		FetchFailed:      fetchFailed,
+	}, nil
+}
+
 // returns all paths to directories in a fileSystem that contain a .git folder
 func listRepoPaths(fileSystem fs.FS) ([]string, error) {
 	var repoPaths []string
@@ -148,44 +204,50 @@ func gitFetch(absPath string) error {
 }
 
 // returns the lastModified time of the most recently modified file/directory
-// in the given fileSystem while ignoring the .git folder
-func getContentLastModifiedTime(fileSystem fs.FS) (time.Time, error) {
+// in the given fileSystem while ignoring the .git folder, along with the total
+// size in bytes of all files including the .git folder since it is part of the
+// space taken up by the repo. Both are found in a single walk of the files
+func getContentLastModifiedTimeAndSize(fileSystem fs.FS) (time.Time, int64, error) {
 	dirInfo, err := fs.Stat(fileSystem, ".")
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, 0, err
 	}
 	lastModified := dirInfo.ModTime()
+	var size int64
 	// recursively traverse through the directory and update the last modified
 	// time if any file or folder is found with an even later last modified
 	err = fs.WalkDir(fileSystem, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			size += info.Size()
+		}
 		// ignore .git folder's last modified date since it can change
 		// when running git status even though the repo's contents have
 		// not changed
-		if d.Name() == ".git" {
-			return fs.SkipDir
-		}
-		subDirInfo, err := d.Info()
-		if err != nil {
-			return err
+		if slices.Contains(strings.Split(path, "/"), ".git") {
+			return nil
 		}
-		if subDirInfo.ModTime().Compare(lastModified) == 1 {
-			lastModified = subDirInfo.ModTime()
+		if info.ModTime().Compare(lastModified) == 1 {
+			lastModified = info.ModTime()
 		}
-
 		return nil
 	})
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, 0, err
 	}
-	return lastModified, nil
+	return lastModified, size, nil
 }
 
 // return a slice of strings describing whether the git repo at absPath
 // has uncommitted changes, branches that are ahead/behind and untracked branches
-func getSyncStatus(absPath string) (bool, []string, error) {
+// along with the number of files with uncommitted changes
+func getSyncStatus(absPath string) (bool, []string, int, error) {
 	// initialize as non-nil empty slice so that json output after marshalling will be []
 	// instead of null
 	statusDescription := []string{}
@@ -194,8 +256,10 @@ func getSyncStatus(absPath string) (bool, []string, error) {
 	cmdCommitStatus.Dir = absPath
 	out, err := cmdCommitStatus.CombinedOutput()
 	if err != nil {
-		return false, nil, errors.New(string(out))
+		return false, nil, 0, errors.New(string(out))
 	}
+	// git status -s outputs a line for each file that has changes
+	uncommittedFiles := strings.Count(string(out), "\n")
 	allChangesCommitted, commitStatusDescription := evaluateCommitSyncStatus(string(out))
 	if commitStatusDescription != "" {
 		statusDescription = append(statusDescription, commitStatusDescription)
@@ -208,26 +272,170 @@ func getSyncStatus(absPath string) (bool, []string, error) {
 	cmdBranchStatus.Dir = absPath
 	out, err = cmdBranchStatus.CombinedOutput()
 	if err != nil {
-		return false, nil, errors.New(string(out))
+		return false, nil, 0, errors.New(string(out))
 	}
 	allBranchesSynced, branchStatusDescription := evaluateBranchSyncStatus(string(out))
 	if branchStatusDescription != nil {
 		statusDescription = append(statusDescription, branchStatusDescription...)
 	}
 	syncedWithRemote := allBranchesSynced && allChangesCommitted
-	return syncedWithRemote, statusDescription, nil
+	return syncedWithRemote, statusDescription, uncommittedFiles, nil
 }
 
-// return the author name of the last commit
-func getLastCommitAuthor(absPath string) (string, error) {
-	cmdFetch := exec.Command("git", "log", "-1", "--pretty=%an")
+// return the author name and author email of the last commit
+func getLastCommitAuthor(absPath string) (string, string, error) {
+	cmdFetch := exec.Command("git", "log", "-1", "--pretty=%an%n%ae")
 	cmdFetch.Dir = absPath
 	out, err := cmdFetch.CombinedOutput()
+	if err != nil {
+		return "", "", errors.New(string(out))
+	}
+	author, authorEmail, _ := strings.Cut(strings.TrimSuffix(string(out), "\n"), "\n")
+	return author, authorEmail, nil
+}
+
+// return the name of the branch that is checked out or "" if HEAD is detached
+func getCurrentBranch(absPath string) (string, error) {
+	cmdBranch := exec.Command("git", "branch", "--show-current")
+	cmdBranch.Dir = absPath
+	out, err := cmdBranch.CombinedOutput()
+	if err != nil {
+		return "", errors.New(string(out))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// return the number of commits the branch is ahead and behind of its upstream
+// branch. Branches without an upstream are neither ahead nor behind
+func getAheadBehind(absPath string, branch string) (int, int, error) {
+	if branch == "" {
+		return 0, 0, nil
+	}
+	cmdTrack := exec.Command("git", "for-each-ref", "--format=%(upstream:track,nobracket)", "refs/heads/"+branch)
+	cmdTrack.Dir = absPath
+	out, err := cmdTrack.CombinedOutput()
+	if err != nil {
+		return 0, 0, errors.New(string(out))
+	}
+	ahead, behind := parseTrack(string(out))
+	return ahead, behind, nil
+}
+
+// parses the output of %(upstream:track,nobracket) which is in the form
+// "ahead 1, behind 2", "ahead 1", "behind 2", "gone" or "" when in sync
+func parseTrack(gitOut string) (int, int) {
+	var ahead, behind int
+	for _, part := range strings.Split(strings.TrimSpace(gitOut), ",") {
+		field, value, _ := strings.Cut(strings.TrimSpace(part), " ")
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		switch field {
+		case "ahead":
+			ahead = count
+		case "behind":
+			behind = count
+		}
+	}
+	return ahead, behind
+}
+
+// return the name of the origin remote, or the first remote if there is no
+// origin. Returns "" if the repo has no remotes
+func getRemoteName(absPath string) (string, error) {
+	cmdRemotes := exec.Command("git", "remote")
+	cmdRemotes.Dir = absPath
+	out, err := cmdRemotes.CombinedOutput()
 	if err != nil {
 		return "", errors.New(string(out))
 	}
-	author := strings.TrimSuffix(string(out), "\n")
-	return author, nil
+	remotes := strings.Fields(string(out))
+	if len(remotes) == 0 {
+		return "", nil
+	}
+	if slices.Contains(remotes, "origin") {
+		return "origin", nil
+	}
+	return remotes[0], nil
+}
+
+// return the url of the origin remote, or the first remote if there is no
+// origin. Returns "" if the repo has no remotes
+func getRemoteURL(absPath string) (string, error) {
+	remote, err := getRemoteName(absPath)
+	if err != nil || remote == "" {
+		return "", err
+	}
+	cmdURL := exec.Command("git", "remote", "get-url", remote)
+	cmdURL.Dir = absPath
+	out, err := cmdURL.CombinedOutput()
+	if err != nil {
+		return "", errors.New(string(out))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// returns the host of a git remote url. Supports urls such as
+// https://host/path, ssh://user@host:port/path and scp-like user@host:path.
+// Remotes that are local paths return "local"
+func remoteHost(remoteURL string) string {
+	if remoteURL == "" {
+		return ""
+	}
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return ""
+		}
+		if u.Scheme == "file" {
+			return "local"
+		}
+		return u.Hostname()
+	}
+	// scp-like syntax is only recognized by git when there is no slash
+	// before the first colon
+	host, _, found := strings.Cut(remoteURL, ":")
+	if !found || strings.Contains(host, "/") {
+		return "local"
+	}
+	// remove the user from the host if there is one
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+	return host
+}
+
+type GitIdentity struct {
+	Name  string
+	Email string
+}
+
+// returns the user.name and user.email from git config as seen from dir.
+// An empty dir uses the current working directory
+func CurrentGitIdentity(dir string) (GitIdentity, error) {
+	var identity GitIdentity
+	for _, key := range []string{"user.name", "user.email"} {
+		cmdConfig := exec.Command("git", "config", key)
+		cmdConfig.Dir = dir
+		out, err := cmdConfig.Output()
+		// git config exits with 1 when the key is not set which is not
+		// an error as long as one of the keys is set
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+			return identity, fmt.Errorf("unable to run git config: %v", err)
+		}
+		value := strings.TrimSpace(string(out))
+		if key == "user.name" {
+			identity.Name = value
+		} else {
+			identity.Email = value
+		}
+	}
+	if identity.Name == "" && identity.Email == "" {
+		return identity, errors.New("user.name and user.email are not set in git config")
+	}
+	return identity, nil
 }
 
 func evaluateCommitSyncStatus(gitOut string) (bool, string) {
@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"io/fs"
 	"reflect"
 	"testing"
 	"testing/fstest"
@@ -38,15 +39,19 @@ func TestListRepoPaths(t *testing.T) {
 func TestGetContentLastModifiedDate(t *testing.T) {
 	tOld, _ := time.Parse(time.RFC3339, "2024-01-01T15:00:00Z")
 	tNew, _ := time.Parse(time.RFC3339, "2024-01-02T13:00:00Z")
+	tGit, _ := time.Parse(time.RFC3339, "2024-01-03T13:00:00Z")
 	testFsys := fstest.MapFS{
-		"test/test1/testfile.test": {ModTime: tOld},
-		"test/test2/testfile.test": {ModTime: tNew},
+		"test/test1/testfile.test": {ModTime: tOld, Data: []byte("abc")},
+		"test/test2/testfile.test": {ModTime: tNew, Data: []byte("de")},
 		"test/testfile.test":       {ModTime: tOld},
+		// .git counts towards the size but not the last modified time
+		".git":       {ModTime: tGit, Mode: fs.ModeDir},
+		".git/index": {ModTime: tGit, Data: []byte("fghij")},
 	}
 	want := tNew
-	got, _ := getContentLastModifiedTime(testFsys)
-	if !got.Equal(want) {
-		t.Errorf("got %v want %v", got, want)
+	got, gotSize, _ := getContentLastModifiedTimeAndSize(testFsys)
+	if !got.Equal(want) || gotSize != 10 {
+		t.Errorf("got %v and size %v want %v and size 10", got, gotSize, want)
 	}
 }
 
@@ -145,3 +150,53 @@ func TestEvaluateBranchSyncStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestRemoteHost(t *testing.T) {
+	var tests = []struct {
+		remoteURL string
+		want      string
+	}{
+		{"https://github.com/bevane/repocheck.git", "github.com"},
+		{"ssh://git@gitlab.example.com:2222/team/repo.git", "gitlab.example.com"},
+		{"git@github.com:bevane/repocheck.git", "github.com"},
+		{"github.com:bevane/repocheck.git", "github.com"},
+		{"file:///srv/git/repo.git", "local"},
+		{"/srv/git/repo.git", "local"},
+		{"../repo.git", "local"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remoteURL, func(t *testing.T) {
+			got := remoteHost(tt.remoteURL)
+			if got != tt.want {
+				t.Errorf("got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrack(t *testing.T) {
+	var tests = []struct {
+		gitOut     string
+		wantAhead  int
+		wantBehind int
+	}{
+		{"", 0, 0},
+		{"ahead 3\n", 3, 0},
+		{"behind 2\n", 0, 2},
+		{"ahead 1, behind 12\n", 1, 12},
+		{"gone\n", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.gitOut, func(t *testing.T) {
+			gotAhead, gotBehind := parseTrack(tt.gitOut)
+			if gotAhead != tt.wantAhead || gotBehind != tt.wantBehind {
+				t.Errorf(
+					"got (%v, %v) , want (%v, %v)",
+					gotAhead, gotBehind,
+					tt.wantAhead, tt.wantBehind,
+				)
+			}
+		})
+	}
+}
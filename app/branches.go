@@ -0,0 +1,228 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/clinaresl/table"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// a local branch of a repo
+type Branch struct {
+	RepoName string `json:"repoName"`
+	RepoPath string `json:"repoPath"`
+	Name     string `json:"name"`
+	// whether the branch is checked out
+	Current bool `json:"current"`
+	// upstream branch such as origin/main, empty when there is none
+	Upstream string `json:"upstream"`
+	// whether the upstream branch no longer exists on the remote
+	UpstreamGone bool      `json:"upstreamGone"`
+	Ahead        int       `json:"ahead"`
+	Behind       int       `json:"behind"`
+	LastCommit   time.Time `json:"lastCommit"`
+	Author       string    `json:"author"`
+	// name of the default branch of the repo, empty when it is not known
+	DefaultBranch string `json:"defaultBranch"`
+	// whether the branch is merged into the default branch. The default
+	// branch itself is not merged
+	Merged bool `json:"merged"`
+}
+
+// returns the local branches of repo in the order of their names
+func GetBranches(repo Repo) ([]Branch, error) {
+	out, err := gitOutput(repo.AbsPath, "for-each-ref",
+		"--format=%(refname:short)%00%(HEAD)%00%(upstream:short)%00%(upstream:track,nobracket)%00%(committerdate:iso-strict)%00%(authorname)",
+		"refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	defaultBranch, defaultRefs := getDefaultBranch(repo.AbsPath)
+	// a branch is merged when it is merged into either the local or the
+	// remote default branch, which can each be ahead of the other
+	merged := map[string]bool{}
+	for _, ref := range defaultRefs {
+		names, err := gitOutput(repo.AbsPath, "for-each-ref", "--merged="+ref, "--format=%(refname:short)", "refs/heads")
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range outputLines(names) {
+			merged[name] = true
+		}
+	}
+	var branches []Branch
+	for _, line := range outputLines(out) {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("unexpected output of git for-each-ref: %q", line)
+		}
+		branch := Branch{
+			RepoName:      repo.Name,
+			RepoPath:      repo.AbsPath,
+			Name:          fields[0],
+			Current:       fields[1] == "*",
+			Upstream:      fields[2],
+			Author:        fields[5],
+			DefaultBranch: defaultBranch,
+			Merged:        merged[fields[0]] && fields[0] != defaultBranch,
+		}
+		branch.UpstreamGone = fields[3] == "gone"
+		branch.Ahead, branch.Behind = parseTrack(fields[3])
+		branch.LastCommit, err = time.Parse(time.RFC3339, fields[4])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected commit date of %v: %v", branch.Name, err)
+		}
+		branches = append(branches, branch)
+	}
+	return branches, nil
+}
+
+// returns the name of the default branch of the repo at absPath and the refs
+// of the local and origin branches of that name that exist. The default
+// branch is the branch that the HEAD of origin points to, otherwise main or
+// master. name is empty when there is no default branch
+func getDefaultBranch(absPath string) (name string, refs []string) {
+	head, err := gitOutput(absPath, "symbolic-ref", "-q", "--short", "refs/remotes/origin/HEAD")
+	candidates := []string{"main", "master"}
+	if err == nil {
+		candidates = []string{strings.TrimPrefix(strings.TrimSpace(head), "origin/")}
+	}
+	for _, candidate := range candidates {
+		for _, ref := range []string{"refs/heads/" + candidate, "refs/remotes/origin/" + candidate} {
+			if gitCommand(absPath, "rev-parse", "--verify", "-q", ref) == nil {
+				refs = append(refs, ref)
+			}
+		}
+		if len(refs) > 0 {
+			return candidate, refs
+		}
+	}
+	return "", nil
+}
+
+// selects branches. Each filter that is set has to match
+type BranchFilter struct {
+	// only branches that are merged into the default branch
+	Merged bool
+	// only branches without an upstream branch
+	NoUpstream bool
+	// only branches without commits since StaleBefore, unless it is zero
+	StaleBefore time.Time
+}
+
+func (f BranchFilter) Match(branch Branch) bool {
+	if f.Merged && !branch.Merged {
+		return false
+	}
+	if f.NoUpstream && branch.Upstream != "" {
+		return false
+	}
+	if !f.StaleBefore.IsZero() && !branch.LastCommit.Before(f.StaleBefore) {
+		return false
+	}
+	return true
+}
+
+// parses an age such as 90d, 2w or a go duration such as 36h
+func ParseAge(value string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(value, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err == nil && count >= 0 {
+				return time.Duration(count) * unit, nil
+			}
+		}
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("%v is not a valid age, use a number of days or weeks such as 90d or 2w or a duration such as 36h", value)
+	}
+	return age, nil
+}
+
+// returns whether branch is merged into the default branch as text
+func mergedText(branch Branch) string {
+	switch {
+	case branch.DefaultBranch == "":
+		return "unknown"
+	case branch.Name == branch.DefaultBranch:
+		return "default"
+	case branch.Merged:
+		return "yes"
+	default:
+		return "no"
+	}
+}
+
+// returns the upstream branch of branch as text, marking upstream branches
+// that no longer exist
+func upstreamText(branch Branch) string {
+	if branch.UpstreamGone {
+		return branch.Upstream + " (gone)"
+	}
+	return branch.Upstream
+}
+
+// returns a table with a row for each branch. The checked out branch is
+// marked with *. Dates are shown in the date format dates
+func ConstructBranchTable(branches []Branch, dates DateFormat) (*table.Table, error) {
+	t, err := table.NewTable("| C{15} | L{20} | L{20} | c | c | c | L{10} | c |")
+	if err != nil {
+		return nil, err
+	}
+	t.AddThickRule()
+	t.AddRow("Repo", "Branch", "Upstream", "Ahead", "Behind", "Last Commit", "Author", "Merged")
+	t.AddThickRule()
+	for _, branch := range branches {
+		name := branch.Name
+		if branch.Current {
+			name = "* " + name
+		}
+		t.AddRow(branch.RepoName, name, upstreamText(branch), branch.Ahead, branch.Behind,
+			dates.Format(branch.LastCommit), branch.Author, mergedText(branch))
+		t.AddSingleRule()
+	}
+	return t, nil
+}
+
+func ConstructBranchTSVOutput(branches []Branch, dates DateFormat) string {
+	output := "Repo\tPath\tBranch\tCurrent\tUpstream\tAhead\tBehind\tLastCommit\tAuthor\tMerged\n"
+	for _, branch := range branches {
+		row := []string{
+			branch.RepoName,
+			branch.RepoPath,
+			branch.Name,
+			strconv.FormatBool(branch.Current),
+			upstreamText(branch),
+			strconv.Itoa(branch.Ahead),
+			strconv.Itoa(branch.Behind),
+			dates.Format(branch.LastCommit),
+			branch.Author,
+			mergedText(branch),
+		}
+		for i := range row {
+			row[i] = escapeTSV(row[i])
+		}
+		output += strings.Join(row, "\t") + "\n"
+	}
+	return output
+}
+
+func ConstructBranchJSONOutput(branches []Branch) string {
+	// initialize as a non-nil empty slice so that json output after
+	// marshalling will be [] instead of null
+	if branches == nil {
+		branches = []Branch{}
+	}
+	jsonOutput, _ := json.MarshalIndent(branches, "", "\t")
+	return string(jsonOutput) + "\n"
+}
+
+// returns the number of branches and the repos they were listed from such as
+// "5 branch(es) in 2 repo(s)"
+func ConstructBranchSummary(branches []Branch, repoCount int) string {
+	return fmt.Sprintf("%v branch(es) in %v repo(s)", len(branches), repoCount)
+}
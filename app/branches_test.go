@@ -0,0 +1,156 @@
+package app
+
+import (
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	var tests = []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90d", 90 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"0d", 0, false},
+		{"-1d", 0, true},
+		{"d", 0, true},
+		{"90", 0, true},
+		{"ninety days", 0, true},
+	}
+	for _, test := range tests {
+		got, err := ParseAge(test.value)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("ParseAge(%q) = %v, %v, want %v and error %v", test.value, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestBranchFilter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	branches := []Branch{
+		{Name: "main", Upstream: "origin/main", DefaultBranch: "main", LastCommit: now},
+		{Name: "old", Upstream: "origin/old", DefaultBranch: "main", Merged: true, LastCommit: now.AddDate(0, -6, 0)},
+		{Name: "local", DefaultBranch: "main", LastCommit: now.AddDate(0, -6, 0)},
+		{Name: "wip", DefaultBranch: "main", Merged: true, LastCommit: now},
+	}
+	var tests = []struct {
+		filter BranchFilter
+		want   []string
+	}{
+		{BranchFilter{}, []string{"main", "old", "local", "wip"}},
+		{BranchFilter{Merged: true}, []string{"old", "wip"}},
+		{BranchFilter{NoUpstream: true}, []string{"local", "wip"}},
+		{BranchFilter{StaleBefore: now.AddDate(0, 0, -90)}, []string{"old", "local"}},
+		{BranchFilter{Merged: true, StaleBefore: now.AddDate(0, 0, -90)}, []string{"old"}},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, branch := range branches {
+			if test.filter.Match(branch) {
+				got = append(got, branch.Name)
+			}
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("%+v: -want +got:\n%s", test.filter, diff)
+		}
+	}
+}
+
+func TestBranchTSVOutput(t *testing.T) {
+	lastCommit := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	branches := []Branch{
+		{RepoName: "a", RepoPath: "/p/a", Name: "main", Current: true, Upstream: "origin/main", Ahead: 1, LastCommit: lastCommit, Author: "x", DefaultBranch: "main"},
+		{RepoName: "a", RepoPath: "/p/a", Name: "done", Upstream: "origin/done", UpstreamGone: true, LastCommit: lastCommit, Author: "x", DefaultBranch: "main", Merged: true},
+		{RepoName: "b", RepoPath: "/p/b", Name: "wip", Behind: 2, LastCommit: lastCommit, Author: "y"},
+	}
+	want := `Repo	Path	Branch	Current	Upstream	Ahead	Behind	LastCommit	Author	Merged
+a	/p/a	main	true	origin/main	1	0	2024-01-02	x	default
+a	/p/a	done	false	origin/done (gone)	0	0	2024-01-02	x	yes
+b	/p/b	wip	false		0	2	2024-01-02	y	unknown
+`
+	if diff := cmp.Diff(want, ConstructBranchTSVOutput(branches, DateFormat{})); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
+// runs the shell commands in script in dir, stopping at the first command that
+// fails
+func runScript(t *testing.T, dir string, script string) {
+	t.Helper()
+	cmd := exec.Command("sh", "-ec", script)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=Test Author", "GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=Test Author", "GIT_COMMITTER_EMAIL=test@test.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+}
+
+func TestGetBranches(t *testing.T) {
+	dir := t.TempDir()
+	origin := filepath.Join(dir, "origin.git")
+	work := filepath.Join(dir, "work")
+	runScript(t, dir, "git init -q --bare -b main origin.git && git init -q -b main work")
+	// merged is merged into main, wip has a commit that is not merged,
+	// local was never pushed, gone was pushed and then deleted on the remote
+	// and remote-merged is only merged into origin/main
+	runScript(t, work, `
+		git remote add origin `+origin+`
+		git commit -q --allow-empty -m first
+		git push -q -u origin main
+		git checkout -q -b merged && git commit -q --allow-empty -m merged
+		git push -q -u origin merged
+		git checkout -q main && git merge -q --ff-only merged && git push -q
+		git checkout -q -b wip && git commit -q --allow-empty -m wip
+		git push -q -u origin wip
+		git checkout -q -b local main && git commit -q --allow-empty -m local
+		git checkout -q -b gone main && git commit -q --allow-empty -m gone
+		git push -q -u origin gone && git push -q origin --delete gone
+		git checkout -q -b remote-merged main && git commit -q --allow-empty -m remote
+		git push -q origin remote-merged:main && git fetch -q
+		git checkout -q main`)
+	branches, err := GetBranches(Repo{Name: "work", AbsPath: work})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, branch := range branches {
+		got = append(got, fmt.Sprintf("%v %v %v gone=%v merged=%v", branch.Name, branch.DefaultBranch, branch.Upstream, branch.UpstreamGone, branch.Merged))
+	}
+	// origin/HEAD is not set so main is found as the default branch, which
+	// is never reported as merged
+	want := []string{
+		"gone main origin/gone gone=true merged=false",
+		"local main  gone=false merged=false",
+		"main main origin/main gone=false merged=false",
+		"merged main origin/merged gone=false merged=true",
+		"remote-merged main  gone=false merged=true",
+		"wip main origin/wip gone=false merged=false",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+	if !branches[2].Current || branches[2].Behind != 1 {
+		t.Errorf("got main current %v and behind %v, want current and behind 1", branches[2].Current, branches[2].Behind)
+	}
+	// the branch that origin/HEAD points to is the default branch even if
+	// there is a main branch
+	runScript(t, work, "git push -q origin main:trunk && git fetch -q && git remote set-head origin trunk")
+	name, refs := getDefaultBranch(work)
+	if name != "trunk" || !slices.Equal(refs, []string{"refs/remotes/origin/trunk"}) {
+		t.Errorf("got default branch %v with refs %v, want trunk with refs/remotes/origin/trunk", name, refs)
+	}
+}
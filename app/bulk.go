@@ -0,0 +1,95 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/clinaresl/table"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// calls fn for each repo with at most jobs calls running at the same time.
+// jobs less than 1 runs one call at a time. fn is given the index of the repo
+// so that results can be stored in the order of repos. repos can be of any
+// type that describes a repo such as Repo or ManifestRepo
+func ForEachRepo[R any](repos []R, jobs int, fn func(i int, repo R)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	var wg sync.WaitGroup
+	// each running call holds a slot until it is done
+	slots := make(chan struct{}, jobs)
+	for i, repo := range repos {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, repo R) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			fn(i, repo)
+		}(i, repo)
+	}
+	wg.Wait()
+}
+
+// the outcome of acting on a repo or on one of its branches
+type Result struct {
+	Repo Repo
+	// the branch that was acted on or "" if the action was on the whole repo
+	Branch string
+	Status string
+	Detail string
+}
+
+// returns a table with the outcome for each result
+func ConstructResultTable(results []Result) (*table.Table, error) {
+	t, err := table.NewTable("| C{15} | L{15} | c | L{40} |")
+	if err != nil {
+		return nil, err
+	}
+	t.AddThickRule()
+	t.AddRow("Repo", "Branch", "Result", "Details")
+	t.AddThickRule()
+	for _, result := range results {
+		t.AddRow(result.Repo.Name, result.Branch, result.Status, result.Detail)
+		t.AddSingleRule()
+	}
+	return t, nil
+}
+
+// returns the number of results with each status such as
+// "2 updated, 1 failed" in the order of the statuses in statuses
+func ConstructResultSummary(results []Result, statuses []string) string {
+	counts := map[string]int{}
+	for _, result := range results {
+		counts[result.Status]++
+	}
+	var parts []string
+	for _, status := range statuses {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%v %v", counts[status], status))
+		}
+	}
+	if len(parts) == 0 {
+		return "no repos matched"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// runs git with args in dir and returns the output of git as the error if it
+// fails
+func gitCommand(dir string, args ...string) error {
+	_, err := gitOutput(dir, args...)
+	return err
+}
+
+// runs git with args in dir and returns its output
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.New(strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
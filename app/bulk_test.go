@@ -0,0 +1,77 @@
+package app
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"sync"
+	"testing"
+)
+
+func TestForEachRepo(t *testing.T) {
+	repos := make([]Repo, 20)
+	for i := range repos {
+		repos[i].Name = string(rune('a' + i))
+	}
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	names := make([]string, len(repos))
+	ForEachRepo(repos, 3, func(i int, repo Repo) {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+		names[i] = repo.Name
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+	if maxRunning > 3 {
+		t.Errorf("%v calls ran at the same time, want at most 3", maxRunning)
+	}
+	for i := range repos {
+		if names[i] != repos[i].Name {
+			t.Errorf("fn was not called with repo %v at index %v", repos[i].Name, i)
+		}
+	}
+}
+
+func TestResultSummary(t *testing.T) {
+	results := []Result{
+		{Status: PullFailed},
+		{Status: PullUpdated},
+		{Status: PullUpdated},
+		{Status: PullSkippedDirty},
+	}
+	want := "2 updated, 1 skipped-dirty, 1 failed"
+	got := ConstructResultSummary(results, PullStatuses)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+	if diff := cmp.Diff("no repos matched", ConstructResultSummary(nil, PullStatuses)); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
+func TestPullRepoSkipped(t *testing.T) {
+	var tests = []struct {
+		repo Repo
+		want Result
+	}{
+		{
+			Repo{Name: "a", Behind: 1},
+			Result{Status: PullNoUpstream, Detail: "detached HEAD"},
+		},
+		{
+			Repo{Name: "b", Branch: "main", Behind: 1, UncommittedFiles: 2},
+			Result{Branch: "main", Status: PullSkippedDirty, Detail: "2 uncommitted file(s)"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.repo.Name, func(t *testing.T) {
+			test.want.Repo = test.repo
+			got := PullRepo(test.repo, false)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
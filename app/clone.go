@@ -0,0 +1,164 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// the outcomes of cloning a repo in the order they are summarized
+var CloneStatuses = []string{CloneCloned, CloneDefaultBranch, CloneWouldClone, CloneExists, CloneDrift, CloneNoRemote, CloneFailed}
+
+// the outcomes of cloning a repo
+const (
+	CloneCloned = "cloned"
+	// the repo was cloned but its branch could not be checked out so the
+	// default branch of the remote is checked out instead
+	CloneDefaultBranch = "default-branch"
+	CloneWouldClone    = "would-clone"
+	CloneExists        = "exists"
+	// the repo exists but does not match the manifest
+	CloneDrift    = "drift"
+	CloneNoRemote = "no-remote"
+	CloneFailed   = "failed"
+)
+
+// reads the json manifest at path that was written by repocheck export
+func LoadManifest(path string) (Manifest, error) {
+	manifest := Manifest{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return manifest, err
+	}
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return manifest, fmt.Errorf("invalid manifest %v: %v", path, err)
+	}
+	if manifest.ManifestVersion > ManifestVersion {
+		return manifest, fmt.Errorf("manifest %v is version %v but only version %v and older are supported", path, manifest.ManifestVersion, ManifestVersion)
+	}
+	return manifest, nil
+}
+
+// clones repo from its clone remote into its path under root, checks out its
+// branch, or its HEAD commit when it was detached, and adds its other remotes.
+// Repos that already exist are not changed and are reported as drift if the
+// url of their clone remote differs from the manifest
+func CloneRepo(repo ManifestRepo, root string, dryRun bool) Result {
+	absPath := filepath.Join(root, filepath.FromSlash(repo.Path))
+	result := Result{
+		Repo:   Repo{Name: filepath.Base(absPath), Path: repo.Path, AbsPath: absPath},
+		Branch: repo.Branch,
+	}
+	// a manifest should never be able to write outside of root
+	if !filepath.IsLocal(filepath.FromSlash(repo.Path)) {
+		result.Status, result.Detail = CloneFailed, "path is outside of the directory"
+		return result
+	}
+	cloneRemote, hasRemote := repo.CloneRemote()
+	_, err := os.Stat(absPath)
+	switch {
+	case err == nil:
+		result.Status, result.Detail = checkDrift(absPath, cloneRemote, hasRemote)
+		return result
+	case !errors.Is(err, fs.ErrNotExist):
+		result.Status, result.Detail = CloneFailed, err.Error()
+		return result
+	case !hasRemote:
+		result.Status = CloneNoRemote
+		return result
+	case dryRun:
+		result.Status = CloneWouldClone
+		result.Detail = "from " + cloneRemote.URL
+		return result
+	}
+	err = gitCommand("", "clone", "-q", "--no-checkout", "-o", cloneRemote.Name, cloneRemote.URL, absPath)
+	if err != nil {
+		result.Status, result.Detail = CloneFailed, err.Error()
+		return result
+	}
+	defaultBranch, err := setUpClone(repo, cloneRemote, absPath)
+	if err != nil {
+		// remove the partial clone so that running clone again retries the
+		// repo instead of reporting it as existing
+		os.RemoveAll(absPath)
+		result.Status, result.Detail = CloneFailed, err.Error()
+		return result
+	}
+	result.Status = CloneCloned
+	if defaultBranch {
+		result.Status = CloneDefaultBranch
+		result.Detail = fmt.Sprintf("branch %v and its commit are not on %v", repo.Branch, cloneRemote.Name)
+	}
+	return result
+}
+
+// checks out the branch of repo in the clone at absPath and adds its other
+// remotes. defaultBranch is true when the default branch of the remote was
+// checked out because the branch could not be
+func setUpClone(repo ManifestRepo, cloneRemote Remote, absPath string) (defaultBranch bool, err error) {
+	defaultBranch, err = checkout(repo, cloneRemote, absPath)
+	if err != nil {
+		return false, err
+	}
+	for _, remote := range repo.Remotes {
+		if remote == cloneRemote {
+			continue
+		}
+		err = gitCommand(absPath, "remote", "add", remote.Name, remote.URL)
+		if err != nil {
+			return false, err
+		}
+	}
+	return defaultBranch, nil
+}
+
+// checks out the branch of repo in the clone at absPath. Branches that were
+// never pushed are created at the HEAD commit if the remote has it. The default
+// branch of the remote is checked out when the branch is not set and there is
+// no commit to check out, or when the branch cannot be created, in which case
+// defaultBranch is true
+func checkout(repo ManifestRepo, cloneRemote Remote, absPath string) (defaultBranch bool, err error) {
+	if repo.Branch == "" && repo.Head != "" {
+		return false, gitCommand(absPath, "checkout", "-q", "--detach", repo.Head)
+	}
+	if repo.Branch == "" {
+		return false, gitCommand(absPath, "checkout", "-q")
+	}
+	// checking out the branch creates it from the remote branch with the
+	// same name
+	err = gitCommand(absPath, "rev-parse", "-q", "--verify", "refs/remotes/"+cloneRemote.Name+"/"+repo.Branch)
+	if err == nil {
+		return false, gitCommand(absPath, "checkout", "-q", repo.Branch)
+	}
+	err = gitCommand(absPath, "cat-file", "-e", repo.Head+"^{commit}")
+	if repo.Head != "" && err == nil {
+		return false, gitCommand(absPath, "checkout", "-q", "-b", repo.Branch, repo.Head)
+	}
+	return true, gitCommand(absPath, "checkout", "-q")
+}
+
+// returns the status of a repo that already exists at absPath
+func checkDrift(absPath string, cloneRemote Remote, hasRemote bool) (string, string) {
+	_, err := os.Stat(filepath.Join(absPath, ".git"))
+	if err != nil {
+		return CloneDrift, "exists but is not a git repo"
+	}
+	if !hasRemote {
+		return CloneExists, ""
+	}
+	remoteURL, err := gitOutput(absPath, "remote", "get-url", cloneRemote.Name)
+	if err != nil {
+		return CloneDrift, fmt.Sprintf("has no %v remote", cloneRemote.Name)
+	}
+	// passwords are left out of manifests
+	remoteURL = redactURL(strings.TrimSpace(remoteURL))
+	if remoteURL != cloneRemote.URL {
+		return CloneDrift, fmt.Sprintf("%v is %v, manifest has %v", cloneRemote.Name, remoteURL, cloneRemote.URL)
+	}
+	return CloneExists, ""
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadManifestNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	err := os.WriteFile(path, []byte(`{"manifestVersion": 2, "repos": []}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadManifest(path)
+	if err == nil || !strings.Contains(err.Error(), "only version 1") {
+		t.Errorf("got error %v, want an error about the version", err)
+	}
+}
+
+func TestCloneRepoWithoutCloning(t *testing.T) {
+	root := t.TempDir()
+	remotes := []Remote{{"origin", "file:///srv/git/a.git"}}
+	var tests = []struct {
+		repo       ManifestRepo
+		wantStatus string
+	}{
+		{ManifestRepo{Path: "../a", Remotes: remotes}, CloneFailed},
+		{ManifestRepo{Path: "/a", Remotes: remotes}, CloneFailed},
+		{ManifestRepo{Path: "a", Remotes: []Remote{}}, CloneNoRemote},
+		{ManifestRepo{Path: "a", Remotes: remotes}, CloneWouldClone},
+	}
+	for _, test := range tests {
+		got := CloneRepo(test.repo, root, true)
+		if got.Status != test.wantStatus {
+			t.Errorf("%v: got %v, want %v", test.repo.Path, got.Status, test.wantStatus)
+		}
+	}
+}
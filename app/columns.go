@@ -0,0 +1,276 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// a column that can be shown in the outputs. Each output format uses the
+// fields relevant to it so that all outputs show the same columns
+type column struct {
+	// name used to select the column with --columns
+	name string
+	// header in the table output
+	tableHeader string
+	// header in the tsv output
+	header string
+	// key in the json output
+	jsonKey string
+	// column spec for github.com/clinaresl/table
+	tableSpec string
+	// the value of the column as text for a repo
+	text func(Repo) string
+	// optional time value of the column, which is shown in the date format of
+	// the output instead of text when it is set
+	time func(Repo) time.Time
+	// optional value for the table output if it differs from text
+	tableText func(Repo) string
+	// the value of the column for a repo in the json output
+	jsonValue func(Repo) any
+	// optional value for outputs that can show a list such as markdown and
+	// html. The list is shown instead of text when it is set
+	list func(Repo) []string
+	// optional value for the styled table if it differs from the table value
+	styledText func(Repo) string
+	// optional value used to sort the column in the html report if sorting
+	// by text would not give the right order
+	sortText func(Repo) string
+	// truncate the value in the middle instead of wrapping it in the styled
+	// table
+	truncate bool
+}
+
+// add columns that can be selected here. The order of the columns in this
+// slice is the order they are listed in when showing the valid columns
+var columns = []column{
+	{
+		name:        "name",
+		tableHeader: "Repo",
+		header:      "Name",
+		jsonKey:     "name",
+		tableSpec:   "C{15}",
+		text:        func(r Repo) string { return r.Name },
+		jsonValue:   func(r Repo) any { return r.Name },
+	},
+	{
+		name:        "path",
+		tableHeader: "Path",
+		header:      "Path",
+		jsonKey:     "path",
+		tableSpec:   "L{20}",
+		text:        func(r Repo) string { return r.AbsPath },
+		jsonValue:   func(r Repo) any { return r.AbsPath },
+		truncate:    true,
+	},
+	{
+		name:        "relpath",
+		tableHeader: "Relative Path",
+		header:      "RelPath",
+		jsonKey:     "relPath",
+		tableSpec:   "L{20}",
+		text:        func(r Repo) string { return r.Path },
+		jsonValue:   func(r Repo) any { return r.Path },
+		truncate:    true,
+	},
+	{
+		name:        "author",
+		tableHeader: "Author",
+		header:      "Author",
+		jsonKey:     "author",
+		tableSpec:   "L{10}",
+		text:        func(r Repo) string { return r.Author },
+		jsonValue:   func(r Repo) any { return r.Author },
+	},
+	{
+		name:        "authoremail",
+		tableHeader: "Author Email",
+		header:      "AuthorEmail",
+		jsonKey:     "authorEmail",
+		tableSpec:   "L{20}",
+		text:        func(r Repo) string { return r.AuthorEmail },
+		jsonValue:   func(r Repo) any { return r.AuthorEmail },
+	},
+	{
+		name:        "lastmodified",
+		tableHeader: "Last Modified",
+		header:      "LastModified",
+		jsonKey:     "lastModified",
+		tableSpec:   "c",
+		time:        func(r Repo) time.Time { return r.LastModified },
+		jsonValue:   func(r Repo) any { return r.LastModified },
+		sortText:    func(r Repo) string { return r.LastModified.UTC().Format(time.RFC3339) },
+	},
+	{
+		name:        "synced",
+		tableHeader: "Synced",
+		header:      "Synced",
+		jsonKey:     "synced",
+		tableSpec:   "c",
+		text:        func(r Repo) string { return strconv.FormatBool(r.SyncedWithRemote) },
+		jsonValue:   func(r Repo) any { return r.SyncedWithRemote },
+		styledText: func(r Repo) string {
+			if r.SyncedWithRemote {
+				return "✓"
+			}
+			return "✗"
+		},
+	},
+	{
+		name:        "syncdetails",
+		tableHeader: "Sync Details",
+		header:      "SyncDetails",
+		jsonKey:     "syncDetails",
+		tableSpec:   "L{23}",
+		text:        func(r Repo) string { return strings.Join(r.SyncDetails, ", ") },
+		tableText: func(r Repo) string {
+			prettySyncDetails := ""
+			// format sync details so that each detail is in its own line
+			for _, line := range r.SyncDetails {
+				prettySyncDetails += "- " + line + "\n"
+			}
+			return prettySyncDetails
+		},
+		jsonValue: func(r Repo) any {
+			// output [] instead of null for repos without details
+			if r.SyncDetails == nil {
+				return []string{}
+			}
+			return r.SyncDetails
+		},
+		list: func(r Repo) []string { return r.SyncDetails },
+	},
+	{
+		name:        "branch",
+		tableHeader: "Branch",
+		header:      "Branch",
+		jsonKey:     "branch",
+		tableSpec:   "L{15}",
+		text:        func(r Repo) string { return r.Branch },
+		jsonValue:   func(r Repo) any { return r.Branch },
+	},
+	{
+		name:        "ahead",
+		tableHeader: "Ahead",
+		header:      "Ahead",
+		jsonKey:     "ahead",
+		tableSpec:   "c",
+		text:        func(r Repo) string { return strconv.Itoa(r.Ahead) },
+		jsonValue:   func(r Repo) any { return r.Ahead },
+	},
+	{
+		name:        "behind",
+		tableHeader: "Behind",
+		header:      "Behind",
+		jsonKey:     "behind",
+		tableSpec:   "c",
+		text:        func(r Repo) string { return strconv.Itoa(r.Behind) },
+		jsonValue:   func(r Repo) any { return r.Behind },
+	},
+	{
+		name:        "remote",
+		tableHeader: "Remote",
+		header:      "Remote",
+		jsonKey:     "remote",
+		tableSpec:   "L{20}",
+		text:        func(r Repo) string { return r.Remote },
+		jsonValue:   func(r Repo) any { return r.Remote },
+		truncate:    true,
+	},
+	{
+		name:        "size",
+		tableHeader: "Size",
+		header:      "Size",
+		jsonKey:     "size",
+		tableSpec:   "r",
+		text:        func(r Repo) string { return strconv.FormatInt(r.Size, 10) },
+		tableText:   func(r Repo) string { return formatSize(r.Size) },
+		jsonValue:   func(r Repo) any { return r.Size },
+	},
+}
+
+// the columns shown in the table and tsv outputs when no columns are selected
+var DefaultColumns = []string{"name", "path", "author", "lastmodified", "synced", "syncdetails"}
+
+// returns the names of all the columns that can be selected
+func ColumnOptions() []string {
+	var options []string
+	for _, c := range columns {
+		options = append(options, c.name)
+	}
+	return options
+}
+
+func ValidateColumns(names []string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("at least one column must be selected. Columns: %v", strings.Join(ColumnOptions(), ", "))
+	}
+	for _, name := range names {
+		if !slices.Contains(ColumnOptions(), strings.ToLower(name)) {
+			return fmt.Errorf("%v is not a valid column. Columns: %v", name, strings.Join(ColumnOptions(), ", "))
+		}
+	}
+	return nil
+}
+
+// returns the columns for the names in the same order as names. Nil names
+// returns the default columns. names must be validated with ValidateColumns
+// first
+func selectColumns(names []string) []column {
+	if names == nil {
+		names = DefaultColumns
+	}
+	var selected []column
+	for _, name := range names {
+		i := slices.IndexFunc(columns, func(c column) bool {
+			return c.name == strings.ToLower(name)
+		})
+		selected = append(selected, columns[i])
+	}
+	return selected
+}
+
+// returns the value of the column as text with times in the date format dates
+func (c column) textValue(repo Repo, dates DateFormat) string {
+	if c.time != nil {
+		return dates.Format(c.time(repo))
+	}
+	return c.text(repo)
+}
+
+func (c column) tableValue(repo Repo, dates DateFormat) string {
+	if c.tableText != nil {
+		return c.tableText(repo)
+	}
+	return c.textValue(repo, dates)
+}
+
+func (c column) sortValue(repo Repo, dates DateFormat) string {
+	if c.sortText != nil {
+		return c.sortText(repo)
+	}
+	return c.textValue(repo, dates)
+}
+
+func (c column) styledValue(repo Repo, dates DateFormat) string {
+	if c.styledText != nil {
+		return c.styledText(repo)
+	}
+	return c.tableValue(repo, dates)
+}
+
+// returns size in bytes in a human readable form using powers of 1024
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
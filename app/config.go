@@ -0,0 +1,108 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+)
+
+// a view is a named set of flag values that is applied as if the flags had
+// been passed on the command line. The keys are the long flag names
+type View map[string]any
+
+type Config struct {
+	Views map[string]View `json:"views"`
+}
+
+// returns the path to the repocheck config file. The path can be overridden
+// with the REPOCHECK_CONFIG environment variable
+func ConfigPath() (string, error) {
+	if path := os.Getenv("REPOCHECK_CONFIG"); path != "" {
+		return path, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "repocheck", "config.json"), nil
+}
+
+// reads and parses the config file at path. A missing config file is not an
+// error and results in an empty config since the config file is optional
+func LoadConfig(path string) (Config, error) {
+	config := Config{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return config, fmt.Errorf("invalid config file %v: %v", path, err)
+	}
+	return config, nil
+}
+
+// returns the names of all the views in the config sorted alphabetically
+func (c Config) ViewNames() []string {
+	var names []string
+	for name := range c.Views {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// returns the view with the given name or an error listing the available
+// views if it does not exist
+func (c Config) View(name string) (View, error) {
+	view, ok := c.Views[name]
+	if !ok {
+		return nil, fmt.Errorf("view %v does not exist. Views: %v", name, c.ViewNames())
+	}
+	return view, nil
+}
+
+// returns the values in the view as strings that can be passed to flags.
+// A list results in multiple values for the same flag so that repeatable
+// flags can be set more than once
+func (v View) FlagValues() (map[string][]string, error) {
+	flagValues := map[string][]string{}
+	for name, value := range v {
+		var values []any
+		if list, ok := value.([]any); ok {
+			values = list
+		} else {
+			values = []any{value}
+		}
+		for _, value := range values {
+			s, err := flagValueString(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %v in view: %v", name, err)
+			}
+			flagValues[name] = append(flagValues[name], s)
+		}
+	}
+	return flagValues, nil
+}
+
+// converts a value decoded from json into the string form accepted by flags
+func flagValueString(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("%v must be a string, number, boolean or a list of those", v)
+	}
+}
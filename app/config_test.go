@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{
+	"views": {
+		"mine-dirty": {"author": ["Foo", "Bar"], "synced": "n", "tsv": true, "limit": 5},
+		"recent": {"sort": "lastmodified", "reverse": true}
+	}
+}`
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("got (%v)\nwant (%v)", err, nil)
+	}
+	wantNames := []string{"mine-dirty", "recent"}
+	if gotNames := config.ViewNames(); !reflect.DeepEqual(gotNames, wantNames) {
+		t.Errorf("got (%v)\nwant (%v)", gotNames, wantNames)
+	}
+	view, err := config.View("mine-dirty")
+	if err != nil {
+		t.Fatalf("got (%v)\nwant (%v)", err, nil)
+	}
+	want := map[string][]string{
+		"author": {"Foo", "Bar"},
+		"synced": {"n"},
+		"tsv":    {"true"},
+		"limit":  {"5"},
+	}
+	got, err := view.FlagValues()
+	if !reflect.DeepEqual(got, want) || err != nil {
+		t.Errorf(
+			"got (%v, %v)\nwant (%v, %v)",
+			got, err,
+			want, nil,
+		)
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	// the config file is optional so a missing file is not an error
+	config, err := LoadConfig(filepath.Join(t.TempDir(), "config.json"))
+	if len(config.Views) != 0 || err != nil {
+		t.Errorf(
+			"got (%v, %v)\nwant (%v, %v)",
+			config, err,
+			Config{}, nil,
+		)
+	}
+}
+
+func TestViewError(t *testing.T) {
+	config := Config{Views: map[string]View{"recent": {"sort": "lastmodified"}}}
+	wantE := fmt.Errorf("view invalid does not exist. Views: [recent]")
+	_, gotE := config.View("invalid")
+	if gotE == nil || gotE.Error() != wantE.Error() {
+		t.Errorf(
+			"got (%v)\nwant (%v)",
+			gotE, wantE,
+		)
+	}
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// named date formats and their go time layouts. An empty layout shows the
+// time relative to now such as "3 days ago"
+var dateFormats = map[string]string{
+	"date":     time.DateOnly,
+	"datetime": "2006-01-02 15:04",
+	"rfc3339":  time.RFC3339,
+	"relative": "",
+}
+
+// how dates are shown in the text outputs. The zero value shows dates as
+// yyyy-mm-dd in their own time zone
+type DateFormat struct {
+	// go time layout, time.DateOnly when empty
+	Layout string
+	// shows dates relative to now such as "3 days ago" instead of using Layout
+	Relative bool
+	// time zone dates are shown in, their own time zone when nil
+	Location *time.Location
+}
+
+// a date format is either one of the named formats or a go time layout such as
+// "02 Jan 2006"
+func ValidateDateFormat(value string) error {
+	if _, ok := dateFormats[strings.ToLower(value)]; ok {
+		return nil
+	}
+	// a layout without any layout elements would show the same text for
+	// every date, which is most likely a typo of one of the named formats
+	reference := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	if reference.Format(value) == value {
+		return fmt.Errorf("%v is not a valid date format. Options: date | datetime | rfc3339 | relative | a go time layout such as \"02 Jan 2006\"", value)
+	}
+	return nil
+}
+
+// returns the date format for value, which must be validated with
+// ValidateDateFormat first. Dates are shown in their own time zone when loc is
+// nil
+func NewDateFormat(value string, loc *time.Location) DateFormat {
+	layout, ok := dateFormats[strings.ToLower(value)]
+	if !ok {
+		layout = value
+	}
+	return DateFormat{Layout: layout, Relative: layout == "", Location: loc}
+}
+
+// returns t in the date format
+func (d DateFormat) Format(t time.Time) string {
+	if d.Relative {
+		return formatRelative(t, time.Now())
+	}
+	if d.Location != nil {
+		t = t.In(d.Location)
+	}
+	layout := d.Layout
+	if layout == "" {
+		layout = time.DateOnly
+	}
+	return t.Format(layout)
+}
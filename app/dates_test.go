@@ -0,0 +1,61 @@
+package app
+
+import (
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+func TestDateFormat(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	date := time.Date(2024, 1, 1, 23, 30, 0, 0, time.UTC)
+	var tests = []struct {
+		format   string
+		location *time.Location
+		want     string
+	}{
+		{"date", nil, "2024-01-01"},
+		{"date", tokyo, "2024-01-02"},
+		{"datetime", time.UTC, "2024-01-01 23:30"},
+		{"RFC3339", tokyo, "2024-01-02T08:30:00+09:00"},
+		{"02 Jan 2006", nil, "01 Jan 2024"},
+	}
+	for _, test := range tests {
+		testname := fmt.Sprintf("%v %v", test.format, test.location)
+		t.Run(testname, func(t *testing.T) {
+			if err := ValidateDateFormat(test.format); err != nil {
+				t.Fatal(err)
+			}
+			got := NewDateFormat(test.format, test.location).Format(date)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestZeroDateFormat(t *testing.T) {
+	date := time.Date(2024, 1, 1, 23, 30, 0, 0, time.UTC)
+	if got := (DateFormat{}).Format(date); got != "2024-01-01" {
+		t.Errorf("got %v, want 2024-01-01", got)
+	}
+}
+
+func TestValidateDateFormatError(t *testing.T) {
+	wantE := `dates is not a valid date format. Options: date | datetime | rfc3339 | relative | a go time layout such as "02 Jan 2006"`
+	gotE := ValidateDateFormat("dates")
+	if gotE == nil || gotE.Error() != wantE {
+		t.Errorf("got (%v)\nwant (%v)", gotE, wantE)
+	}
+}
+
+func TestFormatRelativeDate(t *testing.T) {
+	got := NewDateFormat("relative", nil).Format(time.Now().Add(-3 * 24 * time.Hour))
+	if diff := cmp.Diff("3 days ago", got); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
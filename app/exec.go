@@ -0,0 +1,88 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+// the outcomes of running a command in a repo in the order they are
+// summarized
+var ExecStatuses = []string{ExecOK, ExecFailed, ExecSkipped}
+
+// the outcomes of running a command in a repo
+const (
+	ExecOK      = "ok"
+	ExecFailed  = "failed"
+	ExecSkipped = "skipped"
+)
+
+// runs command in the directory of repo with the output and errors of the
+// command written to w. command is the name of the program followed by its
+// arguments
+func RunInRepo(repo Repo, command []string, w io.Writer) Result {
+	result := Result{Repo: repo, Status: ExecOK}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = repo.AbsPath
+	cmd.Stdout = w
+	cmd.Stderr = w
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		result.Status = ExecFailed
+		result.Detail = fmt.Sprintf("exit status %v", exitErr.ExitCode())
+	case err != nil:
+		result.Status, result.Detail = ExecFailed, err.Error()
+	}
+	return result
+}
+
+// a writer that starts each line written to w with prefix. Lines are only
+// written to w once they are complete so that lines from writers for
+// different repos that share w are not mixed. Close writes any incomplete
+// last line
+type PrefixWriter struct {
+	w      io.Writer
+	prefix string
+	// shared by the writers that write to w
+	mu  *sync.Mutex
+	buf bytes.Buffer
+}
+
+func NewPrefixWriter(w io.Writer, prefix string, mu *sync.Mutex) *PrefixWriter {
+	return &PrefixWriter{w: w, prefix: prefix, mu: mu}
+}
+
+func (p *PrefixWriter) Write(b []byte) (int, error) {
+	p.buf.Write(b)
+	for {
+		i := bytes.IndexByte(p.buf.Bytes(), '\n')
+		if i == -1 {
+			return len(b), nil
+		}
+		err := p.writeLine(p.buf.Next(i + 1))
+		if err != nil {
+			return len(b), err
+		}
+	}
+}
+
+func (p *PrefixWriter) Close() error {
+	if p.buf.Len() == 0 {
+		return nil
+	}
+	line := append(p.buf.Bytes(), '\n')
+	p.buf.Reset()
+	return p.writeLine(line)
+}
+
+func (p *PrefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.w.Write(append([]byte(p.prefix), line...))
+	return err
+}
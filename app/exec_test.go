@@ -0,0 +1,47 @@
+package app
+
+import (
+	"bytes"
+	"github.com/google/go-cmp/cmp"
+	"sync"
+	"testing"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	var mu sync.Mutex
+	a := NewPrefixWriter(&buf, "[a] ", &mu)
+	b := NewPrefixWriter(&buf, "[b] ", &mu)
+	a.Write([]byte("one\ntw"))
+	b.Write([]byte("three\n"))
+	a.Write([]byte("o\nfour"))
+	a.Close()
+	b.Close()
+	want := "[a] one\n[b] three\n[a] two\n[a] four\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
+func TestRunInRepo(t *testing.T) {
+	dir := t.TempDir()
+	var tests = []struct {
+		command    []string
+		wantStatus string
+		wantDetail string
+		wantOutput string
+	}{
+		{[]string{"sh", "-c", "pwd"}, ExecOK, "", dir + "\n"},
+		{[]string{"sh", "-c", "echo err >&2; exit 3"}, ExecFailed, "exit status 3", "err\n"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		got := RunInRepo(Repo{AbsPath: dir}, test.command, &buf)
+		if got.Status != test.wantStatus || got.Detail != test.wantDetail {
+			t.Errorf("%v: got %v %q, want %v %q", test.command, got.Status, got.Detail, test.wantStatus, test.wantDetail)
+		}
+		if diff := cmp.Diff(test.wantOutput, buf.String()); diff != "" {
+			t.Errorf("%v: -want +got:\n%s", test.command, diff)
+		}
+	}
+}
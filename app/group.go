@@ -0,0 +1,101 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// a bucket of repos that share the same value for a group by key along with
+// counts for the repos in the bucket
+type Group struct {
+	Key          string    `json:"key" doc:"value of the group by option shared by the repos"`
+	Total        int       `json:"total" doc:"number of repos in the group"`
+	Unsynced     int       `json:"unsynced" doc:"number of repos in the group that are not synced"`
+	LastActivity time.Time `json:"lastActivity" doc:"latest last modified time of the repos in the group"`
+	Repos        []Repo    `json:"repos"`
+}
+
+type groupKeyFunc func(Repo) string
+
+// add possible group by flag values and the functions returning the group key
+// for a repo here
+var groupByOptions = map[string]groupKeyFunc{
+	"author": func(repo Repo) string {
+		return valueOrPlaceholder(repo.Author, "(unknown)")
+	},
+	"parent-dir": func(repo Repo) string {
+		return filepath.Dir(repo.Path)
+	},
+	"remote-host": func(repo Repo) string {
+		return valueOrPlaceholder(remoteHost(repo.Remote), "(no remote)")
+	},
+	"branch": func(repo Repo) string {
+		return valueOrPlaceholder(repo.Branch, "(detached)")
+	},
+	"synced": func(repo Repo) string {
+		if repo.SyncedWithRemote {
+			return "synced"
+		}
+		return "not synced"
+	},
+}
+
+func valueOrPlaceholder(value string, placeholder string) string {
+	if value == "" {
+		return placeholder
+	}
+	return value
+}
+
+// returns the names of the options accepted by GroupRepos in alphabetical
+// order
+func GroupByOptions() []string {
+	var options []string
+	for key := range groupByOptions {
+		options = append(options, key)
+	}
+	slices.Sort(options)
+	return options
+}
+
+func ValidateGroupBy(value string) error {
+	_, ok := groupByOptions[strings.ToLower(value)]
+	if !ok {
+		return fmt.Errorf("%v is not a valid group by option. Options: %v", value, strings.Join(GroupByOptions(), " | "))
+	}
+	return nil
+}
+
+// buckets repos by the group by key. The groups are sorted by key and the
+// repos within each group keep the order they had in repos. value must be
+// validated with ValidateGroupBy first
+func GroupRepos(repos []Repo, value string) []Group {
+	groupKey := groupByOptions[strings.ToLower(value)]
+	var groups []Group
+	groupIndexes := map[string]int{}
+	for _, repo := range repos {
+		key := groupKey(repo)
+		i, ok := groupIndexes[key]
+		if !ok {
+			groups = append(groups, Group{Key: key, Repos: []Repo{}})
+			i = len(groups) - 1
+			groupIndexes[key] = i
+		}
+		group := &groups[i]
+		group.Repos = append(group.Repos, repo)
+		group.Total++
+		if !repo.SyncedWithRemote {
+			group.Unsynced++
+		}
+		if repo.LastModified.After(group.LastActivity) {
+			group.LastActivity = repo.LastModified
+		}
+	}
+	slices.SortFunc(groups, func(a, b Group) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return groups
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestGroupRepos(t *testing.T) {
+	repos := []Repo{
+		{Name: "x", Path: "team-a/x", LastModified: jan1, SyncedWithRemote: true, Branch: "main"},
+		{Name: "z", Path: "team-b/z", LastModified: jan3, SyncedWithRemote: false, Branch: ""},
+		{Name: "y", Path: "team-a/y", LastModified: jan2, SyncedWithRemote: false, Branch: "main"},
+	}
+	var tests = []struct {
+		key  string
+		want []Group
+	}{
+		{
+			"parent-dir",
+			[]Group{
+				{Key: "team-a", Total: 2, Unsynced: 1, LastActivity: jan2, Repos: []Repo{repos[0], repos[2]}},
+				{Key: "team-b", Total: 1, Unsynced: 1, LastActivity: jan3, Repos: []Repo{repos[1]}},
+			},
+		},
+		{
+			"branch",
+			[]Group{
+				{Key: "(detached)", Total: 1, Unsynced: 1, LastActivity: jan3, Repos: []Repo{repos[1]}},
+				{Key: "main", Total: 2, Unsynced: 1, LastActivity: jan2, Repos: []Repo{repos[0], repos[2]}},
+			},
+		},
+		{
+			"synced",
+			[]Group{
+				{Key: "not synced", Total: 2, Unsynced: 2, LastActivity: jan3, Repos: []Repo{repos[1], repos[2]}},
+				{Key: "synced", Total: 1, Unsynced: 0, LastActivity: jan1, Repos: []Repo{repos[0]}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			got := GroupRepos(repos, test.key)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got (%v)\nwant (%v)", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGroupByError(t *testing.T) {
+	wantE := fmt.Errorf("invalid is not a valid group by option. Options: author | branch | parent-dir | remote-host | synced")
+	gotE := ValidateGroupBy("invalid")
+	if gotE == nil || gotE.Error() != wantE.Error() {
+		t.Errorf(
+			"got (%v)\nwant (%v)",
+			gotE, wantE,
+		)
+	}
+}
@@ -1,72 +1,305 @@
 package app
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"github.com/clinaresl/table"
+	"io"
+	"strconv"
 	"strings"
+	"time"
 )
 
-func ConstructJSONOutput(repos []Repo) string {
-	jsonOutput, _ := json.MarshalIndent(&repos, "", "\t")
+// columnNames selects the fields of each repo in the output in the given
+// order. All fields are included when columnNames is nil
+func ConstructJSONOutput(output Output, columnNames []string) string {
+	// same fields as Group but with the repos already marshalled so that
+	// only the selected columns are included
+	type projectedGroup struct {
+		Key          string            `json:"key"`
+		Total        int               `json:"total"`
+		Unsynced     int               `json:"unsynced"`
+		LastActivity time.Time         `json:"lastActivity"`
+		Repos        []json.RawMessage `json:"repos"`
+	}
+	var groups []projectedGroup
+	for _, group := range output.Groups {
+		groups = append(groups, projectedGroup{group.Key, group.Total, group.Unsynced, group.LastActivity, marshalRepos(group.Repos, columnNames)})
+	}
+	// same fields as Output but with the repos already marshalled
+	projected := struct {
+		SchemaVersion int               `json:"schemaVersion"`
+		GeneratedAt   time.Time         `json:"generatedAt"`
+		Root          string            `json:"root"`
+		Summary       Summary           `json:"summary"`
+		Repos         []json.RawMessage `json:"repos"`
+		Groups        []projectedGroup  `json:"groups,omitempty"`
+	}{output.SchemaVersion, output.GeneratedAt, output.Root, output.Summary, marshalRepos(output.Repos, columnNames), groups}
+	jsonOutput, _ := json.MarshalIndent(&projected, "", "\t")
 	// add new line at the end because Marshal does not end the output with newline
 	return string(jsonOutput) + "\n"
 }
 
-func ConstructTSVOutput(repos []Repo) string {
-	output := "Name\tPath\tAuthor\tLastModified\tSynced\tSyncDetails\n"
+func marshalRepos(repos []Repo, columnNames []string) []json.RawMessage {
+	marshalled := []json.RawMessage{}
 	for _, repo := range repos {
-		year, month, day := repo.LastModified.Date()
-		lastModifiedDate := fmt.Sprintf("%04d-%02d-%02d", year, int(month), day)
-		row := fmt.Sprintf("%s\t%s\t%s\t%s\t%t\t%s\n", repo.Name, repo.AbsPath, repo.Author, lastModifiedDate, repo.SyncedWithRemote, strings.Join(repo.SyncDetails, ", "))
-		output += row
+		marshalled = append(marshalled, marshalRepo(repo, columnNames))
+	}
+	return marshalled
+}
+
+// returns the repo as compact json with only the fields for columnNames in
+// the same order. All fields are included when columnNames is nil
+func marshalRepo(repo Repo, columnNames []string) []byte {
+	if columnNames == nil {
+		// output [] instead of null for repos without details
+		if repo.SyncDetails == nil {
+			repo.SyncDetails = []string{}
+		}
+		jsonOutput, _ := json.Marshal(repo)
+		return jsonOutput
+	}
+	// marshal each field separately because marshalling a map would sort
+	// the fields by key instead of keeping the order of the columns
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, c := range selectColumns(columnNames) {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key, _ := json.Marshal(c.jsonKey)
+		value, _ := json.Marshal(c.jsonValue(repo))
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes()
+}
+
+// writes newline delimited json with one repo per line so that each repo can
+// be written as soon as it is found
+type NDJSONWriter struct {
+	w           io.Writer
+	columnNames []string
+}
+
+// columnNames selects the fields of each repo in the given order. All fields
+// are included when columnNames is nil
+func NewNDJSONWriter(w io.Writer, columnNames []string) *NDJSONWriter {
+	return &NDJSONWriter{w: w, columnNames: columnNames}
+}
+
+func (n *NDJSONWriter) WriteRepo(repo Repo) error {
+	_, err := n.w.Write(append(marshalRepo(repo, n.columnNames), '\n'))
+	return err
+}
+
+// writes the summary of the repos found in root as the last record in the form
+// {"schemaVersion": 1, "root": "...", "summary": {...}} so that it can be told
+// apart from the repos. The repo records follow the same schema version as the
+// json output
+func (n *NDJSONWriter) WriteSummary(root string, summary Summary) error {
+	record := struct {
+		SchemaVersion int     `json:"schemaVersion"`
+		Root          string  `json:"root"`
+		Summary       Summary `json:"summary"`
+	}{SchemaVersion, root, summary}
+	jsonOutput, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = n.w.Write(append(jsonOutput, '\n'))
+	return err
+}
+
+// columnNames selects the columns in the output in the given order. The
+// default columns are used when columnNames is nil. Dates are shown in the
+// date format dates
+func ConstructTSVOutput(repos []Repo, columnNames []string, dates DateFormat) string {
+	header, rows := constructRows(repos, columnNames, dates)
+	output := strings.Join(header, "\t") + "\n"
+	for _, row := range rows {
+		for i := range row {
+			row[i] = escapeTSV(row[i])
+		}
+		output += strings.Join(row, "\t") + "\n"
 	}
 	return output
 }
 
-func ConstructSummary(repos []Repo, root string) string {
-	countRepos := len(repos)
-	var countUnsynced int
+// columnNames selects the columns in the output in the given order. The
+// default columns are used when columnNames is nil. Dates are shown in the
+// date format dates
+func ConstructCSVOutput(repos []Repo, columnNames []string, dates DateFormat) string {
+	header, rows := constructRows(repos, columnNames, dates)
+	return writeCSV(header, rows)
+}
+
+// returns the header and the text value of each selected column for each repo
+func constructRows(repos []Repo, columnNames []string, dates DateFormat) ([]string, [][]string) {
+	columns := selectColumns(columnNames)
+	var header []string
+	for _, c := range columns {
+		header = append(header, c.header)
+	}
+	var rows [][]string
+	for _, repo := range repos {
+		var row []string
+		for _, c := range columns {
+			row = append(row, c.textValue(repo, dates))
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+// escapes characters that would otherwise break up a field or a row in the tsv
+// output. Backslashes are escaped as well so that the output can be unescaped
+var tsvEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"\t", "\\t",
+	"\n", "\\n",
+	"\r", "\\r",
+)
+
+func escapeTSV(value string) string {
+	return tsvEscaper.Replace(value)
+}
+
+// uses encoding/csv so that fields containing commas, quotes or newlines are
+// quoted correctly
+func writeCSV(header []string, rows [][]string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(header)
+	w.WriteAll(rows)
+	return buf.String()
+}
+
+// counts for the repos that matched the queries
+type Summary struct {
+	Total    int    `json:"total" doc:"number of repos that matched the filters"`
+	Unsynced int    `json:"unsynced" doc:"number of those repos that are not synced"`
+	Stats    Stats  `json:"stats" doc:"statistics for those repos"`
+}
+
+func Summarize(repos []Repo) Summary {
+	summary := Summary{Total: len(repos), Stats: computeStats(repos)}
 	for _, repo := range repos {
 		if !repo.SyncedWithRemote {
-			countUnsynced++
+			summary.Unsynced++
 		}
 	}
-	return fmt.Sprintf(
+	return summary
+}
+
+// repos should be all the repos that matched the queries and shown is the
+// number of those repos that are actually displayed after limit and offset
+// are applied
+func ConstructSummary(repos []Repo, shown int, root string) string {
+	summary := Summarize(repos)
+	output := fmt.Sprintf(
 		"%v repos found in %v: %v repo(s) are not synced",
-		countRepos,
+		summary.Total,
 		root,
-		countUnsynced,
+		summary.Unsynced,
 	)
+	if shown != summary.Total {
+		output += fmt.Sprintf(", showing %v of %v", shown, summary.Total)
+	}
+	return output
 }
 
-func ConstructTable(repos []Repo) (*table.Table, error) {
-	t, err := table.NewTable("| C{15} | L{20} | L{10} | c | c | L{23} |")
+// columnNames selects the columns in the table in the given order. The
+// default columns are used when columnNames is nil. Dates are shown in the
+// date format dates
+func ConstructTable(repos []Repo, columnNames []string, dates DateFormat) (*table.Table, error) {
+	columns := selectColumns(columnNames)
+	var specs []string
+	var headers []any
+	for _, c := range columns {
+		specs = append(specs, c.tableSpec)
+		headers = append(headers, c.tableHeader)
+	}
+	t, err := table.NewTable("| " + strings.Join(specs, " | ") + " |")
 	if err != nil {
 		return nil, err
 	}
 	t.AddThickRule()
-	t.AddRow("Repo", "Path", "Author", "Last Modified", "Synced", "Sync Details")
+	t.AddRow(headers...)
 	t.AddThickRule()
-	for i, repo := range repos {
-		year, month, day := repos[i].LastModified.Date()
-		LastModifiedDate := fmt.Sprintf("%04d-%02d-%02d", year, int(month), day)
-		prettySyncDetails := ""
-		// format sync details so that each detail is in its own line
-		for _, line := range repo.SyncDetails {
-			prettySyncDetails += "- " + line + "\n"
+	for _, repo := range repos {
+		var values []any
+		for _, c := range columns {
+			values = append(values, c.tableValue(repo, dates))
 		}
+		t.AddRow(values...)
+		t.AddSingleRule()
+	}
+	return t, nil
+
+}
+
+// outputs groups within output so that the group output has the same fields
+// as the json output for repos. The repos are only included in their group.
+// columnNames selects the fields of each repo in the same way as for
+// ConstructJSONOutput
+func ConstructGroupJSONOutput(output Output, groups []Group, columnNames []string) string {
+	output.Repos = []Repo{}
+	output.Groups = groups
+	return ConstructJSONOutput(output, columnNames)
+}
 
+func ConstructGroupTSVOutput(groups []Group, dates DateFormat) string {
+	header, rows := constructGroupRows(groups, dates)
+	output := strings.Join(header, "\t") + "\n"
+	for _, row := range rows {
+		for i := range row {
+			row[i] = escapeTSV(row[i])
+		}
+		output += strings.Join(row, "\t") + "\n"
+	}
+	return output
+}
+
+func ConstructGroupCSVOutput(groups []Group, dates DateFormat) string {
+	header, rows := constructGroupRows(groups, dates)
+	return writeCSV(header, rows)
+}
+
+func constructGroupRows(groups []Group, dates DateFormat) ([]string, [][]string) {
+	header := []string{"Group", "Total", "Unsynced", "LastActivity"}
+	var rows [][]string
+	for _, group := range groups {
+		rows = append(rows, []string{
+			group.Key,
+			strconv.Itoa(group.Total),
+			strconv.Itoa(group.Unsynced),
+			dates.Format(group.LastActivity),
+		})
+	}
+	return header, rows
+}
+
+func ConstructGroupTable(groups []Group, dates DateFormat) (*table.Table, error) {
+	t, err := table.NewTable("| L{30} | c | c | c |")
+	if err != nil {
+		return nil, err
+	}
+	t.AddThickRule()
+	t.AddRow("Group", "Repos", "Unsynced", "Last Activity")
+	t.AddThickRule()
+	for _, group := range groups {
 		t.AddRow(
-			repos[i].Name,
-			repos[i].AbsPath,
-			repos[i].Author,
-			LastModifiedDate,
-			repos[i].SyncedWithRemote,
-			prettySyncDetails,
+			group.Key,
+			group.Total,
+			group.Unsynced,
+			dates.Format(group.LastActivity),
 		)
 		t.AddSingleRule()
 	}
 	return t, nil
-
 }
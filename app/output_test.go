@@ -1,9 +1,15 @@
 package app
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 var TSVTests = []struct {
@@ -28,7 +34,7 @@ func TestTSVOutput(t *testing.T) {
 		testname := fmt.Sprintf("%v", test.key)
 		t.Run(testname, func(t *testing.T) {
 			repos := test.input
-			got := ConstructTSVOutput(repos)
+			got := ConstructTSVOutput(repos, nil, DateFormat{})
 			if diff := cmp.Diff(test.want, got); diff != "" {
 				t.Errorf("-want +got:\n%s", diff)
 			}
@@ -36,29 +42,63 @@ func TestTSVOutput(t *testing.T) {
 	}
 }
 
-var JSONTests = []struct {
-	key   string
-	input []Repo
-	want  string
-}{
-	{
-		"short",
-		getInputReposByKey("short"),
-		getJSONOutputByKey("short"),
-	},
-	{
-		"long",
-		getInputReposByKey("long"),
-		getJSONOutputByKey("long"),
-	},
+// the json output is compared against golden files so that changes to the
+// output that could break consumers are deliberate. Fields can be added to the
+// golden files but removing or changing a field requires a new SchemaVersion
+func TestJSONOutput(t *testing.T) {
+	generatedAt := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	for _, key := range []string{"short", "long"} {
+		t.Run(key, func(t *testing.T) {
+			repos := getInputReposByKey(key)
+			output := NewOutput(repos, "/home/repos", Summarize(repos), generatedAt)
+			got := ConstructJSONOutput(output, nil)
+			want, err := os.ReadFile(filepath.Join("testdata", fmt.Sprintf("output-v%v-%v.json", SchemaVersion, key)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(string(want), got); diff != "" {
+				t.Errorf("-want +got:\n%s", diff)
+			}
+		})
+	}
 }
 
-func TestJSONOutput(t *testing.T) {
-	for _, test := range JSONTests {
-		testname := fmt.Sprintf("%v", test.key)
+func TestJSONSchema(t *testing.T) {
+	want, err := os.ReadFile(filepath.Join("..", "docs", "repocheck.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), JSONSchema()); diff != "" {
+		t.Errorf("schema is out of date, run scripts/schema.sh\n-want +got:\n%s", diff)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	var tests = []struct {
+		key   string
+		shown int
+		want  string
+	}{
+		{
+			"long",
+			2,
+			"2 repos found in /home/repos: 2 repo(s) are not synced",
+		},
+		{
+			"long",
+			1,
+			"2 repos found in /home/repos: 2 repo(s) are not synced, showing 1 of 2",
+		},
+		{
+			"short",
+			2,
+			"2 repos found in /home/repos: 0 repo(s) are not synced",
+		},
+	}
+	for _, test := range tests {
+		testname := fmt.Sprintf("%v shown %v", test.key, test.shown)
 		t.Run(testname, func(t *testing.T) {
-			repos := test.input
-			got := ConstructJSONOutput(repos)
+			got := ConstructSummary(getInputReposByKey(test.key), test.shown, "/home/repos")
 			if diff := cmp.Diff(test.want, got); diff != "" {
 				t.Errorf("-want +got:\n%s", diff)
 			}
@@ -66,10 +106,287 @@ func TestJSONOutput(t *testing.T) {
 	}
 }
 
+func TestColumnsOutput(t *testing.T) {
+	repos := getInputReposByKey("long")
+	repos[0].Branch = "main"
+	repos[0].Ahead = 2
+	columnNames := []string{"name", "Branch", "ahead", "behind", "syncdetails"}
+
+	wantTSV := `Name	Branch	Ahead	Behind	SyncDetails
+blink-frost-dune-glimmer	main	2	0	uncommitted changes, untracked branch(es)
+stone-drift-moon-sparkle-breeze		0	0	uncommitted changes, untracked branch(es), branch(es) ahead
+`
+	if diff := cmp.Diff(wantTSV, ConstructTSVOutput(repos, columnNames, DateFormat{})); diff != "" {
+		t.Errorf("tsv -want +got:\n%s", diff)
+	}
+
+	// the fields in the json output are in the order of the columns
+	wantJSON := `{
+	"schemaVersion": 1,
+	"generatedAt": "2024-02-01T12:00:00Z",
+	"root": "/home/repos",
+	"summary": {
+		"total": 2,
+		"unsynced": 2,
+		"stats": {
+			"syncProblems": {
+				"branch(es) ahead": 1,
+				"uncommitted changes": 2,
+				"untracked branch(es)": 2
+			},
+			"authors": {
+				"Test Author": 2
+			},
+			"oldestActivity": "2024-01-01T00:00:00Z",
+			"newestActivity": "2024-01-02T00:00:00Z",
+			"unpushedCommits": 2,
+			"uncommittedFiles": 0,
+			"withoutRemote": 2,
+			"fetchFailures": 0
+		}
+	},
+	"repos": [
+		{
+			"name": "blink-frost-dune-glimmer",
+			"branch": "main",
+			"ahead": 2,
+			"behind": 0,
+			"syncDetails": [
+				"uncommitted changes",
+				"untracked branch(es)"
+			]
+		},
+		{
+			"name": "stone-drift-moon-sparkle-breeze",
+			"branch": "",
+			"ahead": 0,
+			"behind": 0,
+			"syncDetails": [
+				"uncommitted changes",
+				"untracked branch(es)",
+				"branch(es) ahead"
+			]
+		}
+	]
+}
+`
+	output := NewOutput(repos, "/home/repos", Summarize(repos), time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC))
+	if diff := cmp.Diff(wantJSON, ConstructJSONOutput(output, columnNames)); diff != "" {
+		t.Errorf("json -want +got:\n%s", diff)
+	}
+
+	table, err := ConstructTable(repos, []string{"name", "ahead"}, DateFormat{})
+	wantTable := `┍━━━━━━━━━━━━━━━━━┯━━━━━━━┑
+│      Repo       │ Ahead │
+┝━━━━━━━━━━━━━━━━━┿━━━━━━━┥
+│ blink-frost-dun │   2   │
+│    e-glimmer    │       │
+├─────────────────┼───────┤
+│ stone-drift-moo │   0   │
+│ n-sparkle-breez │       │
+│        e        │       │
+└─────────────────┴───────┘`
+	if diff := cmp.Diff(wantTable, fmt.Sprint(table)); diff != "" || err != nil {
+		t.Errorf("table -want +got:\n%s\n%v", diff, err)
+	}
+}
+
+func TestValidateColumnsError(t *testing.T) {
+	wantE := fmt.Errorf("invalid is not a valid column. Columns: name, path, relpath, author, authoremail, lastmodified, synced, syncdetails, branch, ahead, behind, remote, size")
+	gotE := ValidateColumns([]string{"name", "invalid"})
+	if gotE == nil || gotE.Error() != wantE.Error() {
+		t.Errorf(
+			"got (%v)\nwant (%v)",
+			gotE, wantE,
+		)
+	}
+}
+
+// repos with values that need to be escaped or quoted
+func getReposWithSpecialCharacters() []Repo {
+	return []Repo{
+		{
+			Name:        "tabs\tand\nnewlines",
+			AbsPath:     `/home/repos/back\slash, "quoted"`,
+			Author:      "Author\r\nWith CRLF",
+			SyncDetails: []string{"uncommitted changes"},
+		},
+		{
+			Name:    "plain",
+			AbsPath: "/home/repos/plain",
+			Author:  `Literal \t Author`,
+		},
+	}
+}
+
+func TestCSVOutputRoundTrip(t *testing.T) {
+	repos := getReposWithSpecialCharacters()
+	columnNames := []string{"name", "path", "author", "syncdetails"}
+	_, want := constructRows(repos, columnNames, DateFormat{})
+
+	records, err := csv.NewReader(strings.NewReader(ConstructCSVOutput(repos, columnNames, DateFormat{}))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHeader := []string{"Name", "Path", "Author", "SyncDetails"}
+	if diff := cmp.Diff(wantHeader, records[0]); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+	// encoding/csv reads \r\n inside quoted fields as \n
+	want[0][2] = "Author\nWith CRLF"
+	if diff := cmp.Diff(want, records[1:]); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
+// reverses the escaping done for the tsv output
+func unescapeTSV(value string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range value {
+		if !escaped && r == '\\' {
+			escaped = true
+			continue
+		}
+		if escaped {
+			switch r {
+			case 't':
+				r = '\t'
+			case 'n':
+				r = '\n'
+			case 'r':
+				r = '\r'
+			}
+			escaped = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func TestTSVOutputRoundTrip(t *testing.T) {
+	repos := getReposWithSpecialCharacters()
+	columnNames := []string{"name", "path", "author", "syncdetails"}
+	_, want := constructRows(repos, columnNames, DateFormat{})
+
+	lines := strings.Split(strings.TrimSuffix(ConstructTSVOutput(repos, columnNames, DateFormat{}), "\n"), "\n")
+	// each repo must still be on its own line with the same number of fields
+	if len(lines) != len(repos)+1 {
+		t.Fatalf("got %v lines want %v lines", len(lines), len(repos)+1)
+	}
+	var got [][]string
+	for _, line := range lines[1:] {
+		fields := strings.Split(line, "\t")
+		for i := range fields {
+			fields[i] = unescapeTSV(fields[i])
+		}
+		got = append(got, fields)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
+func TestMarkdownOutput(t *testing.T) {
+	repos := getInputReposByKey("long")
+	repos[0].Author = "Pipe | Author"
+	want := `| Repo | Author | Sync Details |
+| --- | --- | --- |
+| blink-frost-dune-glimmer | Pipe \| Author | - uncommitted changes<br>- untracked branch(es) |
+| stone-drift-moon-sparkle-breeze | Test Author | - uncommitted changes<br>- untracked branch(es)<br>- branch(es) ahead |
+
+2 repos found in /home/repos: 2 repo(s) are not synced
+`
+	got := ConstructMarkdownOutput(repos, []string{"name", "author", "syncdetails"}, "2 repos found in /home/repos: 2 repo(s) are not synced", DateFormat{})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
+func TestHTMLOutput(t *testing.T) {
+	repos := append(getInputReposByKey("short"), getInputReposByKey("long")...)
+	repos[0].Author = "<script>alert(1)</script>"
+	got, err := ConstructHTMLOutput(repos, nil, "4 repos found in /home/repos: 2 repo(s) are not synced", DateFormat{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantContains := []string{
+		"<th>Repo</th>",
+		"<th>Sync Details</th>",
+		`<tr class="synced">`,
+		`<tr class="unsynced">`,
+		`<td data-sort="2024-01-02T00:00:00Z">2024-01-02</td>`,
+		"<ul><li>uncommitted changes</li><li>untracked branch(es)</li></ul>",
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+		`<p class="summary">4 repos found in /home/repos: 2 repo(s) are not synced</p>`,
+	}
+	for _, want := range wantContains {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %v", want)
+		}
+	}
+	if strings.Contains(got, "<script>alert(1)</script>") {
+		t.Errorf("output contains unescaped author")
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	var buf strings.Builder
+	writer := NewNDJSONWriter(&buf, []string{"name", "synced"})
+	repos := getInputReposByKey("short")
+	for _, repo := range repos {
+		writer.WriteRepo(repo)
+	}
+	writer.WriteSummary("/home/repos", Summarize(repos))
+	want := `{"name":"wheels","synced":true}
+{"name":"engine","synced":true}
+{"schemaVersion":1,"root":"/home/repos","summary":{"total":2,"unsynced":0,"stats":{"syncProblems":{},"authors":{"Test Author":2},"oldestActivity":"2024-01-01T00:00:00Z","newestActivity":"2024-01-02T00:00:00Z","unpushedCommits":0,"uncommittedFiles":0,"withoutRemote":2,"fetchFailures":0}}}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
+func TestGroupTSVOutput(t *testing.T) {
+	groups := GroupRepos(getInputReposByKey("long"), "synced")
+	want := "Group\tTotal\tUnsynced\tLastActivity\n" +
+		"not synced\t2\t2\t2024-01-02\n"
+	got := ConstructGroupTSVOutput(groups, DateFormat{})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
+func TestGroupJSONOutput(t *testing.T) {
+	repos := getInputReposByKey("short")
+	groups := GroupRepos(repos, "synced")
+	output := NewOutput(repos, "/home/repos", Summarize(repos), jan1)
+	got := ConstructGroupJSONOutput(output, groups, []string{"name"})
+	var decoded struct {
+		Repos  []map[string]any `json:"repos"`
+		Groups []struct {
+			Key   string           `json:"key"`
+			Repos []map[string]any `json:"repos"`
+		} `json:"groups"`
+	}
+	err := json.Unmarshal([]byte(got), &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Repos) != 0 || len(decoded.Groups) != 1 {
+		t.Fatalf("got %v top level repos and %v groups, want 0 and 1", len(decoded.Repos), len(decoded.Groups))
+	}
+	want := []map[string]any{{"name": "wheels"}, {"name": "engine"}}
+	if diff := cmp.Diff(want, decoded.Groups[0].Repos); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
 func getInputReposByKey(key string) []Repo {
 	reposWithShortFields := []Repo{
 		{
 			Name:             "wheels",
+			Path:             "wheels",
 			AbsPath:          "/home/repos/wheels",
 			SyncedWithRemote: true,
 			SyncDetails:      []string{},
@@ -78,6 +395,7 @@ func getInputReposByKey(key string) []Repo {
 		},
 		{
 			Name:             "engine",
+			Path:             "engine",
 			AbsPath:          "/home/repos/engine",
 			SyncedWithRemote: true,
 			SyncDetails:      []string{},
@@ -88,6 +406,7 @@ func getInputReposByKey(key string) []Repo {
 	reposWithLongFields := []Repo{
 		{
 			Name:             "blink-frost-dune-glimmer",
+			Path:             "blink-frost-dune-glimmer",
 			AbsPath:          "/home/repos/blink-frost-dune-glimmer",
 			SyncedWithRemote: false,
 			LastModified:     jan1,
@@ -96,6 +415,7 @@ func getInputReposByKey(key string) []Repo {
 		},
 		{
 			Name:             "stone-drift-moon-sparkle-breeze",
+			Path:             "stone-drift-moon-sparkle-breeze",
 			AbsPath:          "/home/repos/stone-drift-moon-sparkle-breeze",
 			SyncedWithRemote: false,
 			LastModified:     jan2,
@@ -128,57 +448,3 @@ stone-drift-moon-sparkle-breeze	/home/repos/stone-drift-moon-sparkle-breeze	Test
 	}
 	return keyToOutputs[key]
 }
-
-func getJSONOutputByKey(key string) string {
-	outWithShortFields := `[
-	{
-		"name": "wheels",
-		"path": "/home/repos/wheels",
-		"lastModified": "2024-01-01T00:00:00Z",
-		"synced": true,
-		"syncDetails": [],
-		"author": "Test Author"
-	},
-	{
-		"name": "engine",
-		"path": "/home/repos/engine",
-		"lastModified": "2024-01-02T00:00:00Z",
-		"synced": true,
-		"syncDetails": [],
-		"author": "Test Author"
-	}
-]
-`
-	outWithLongFields := `[
-	{
-		"name": "blink-frost-dune-glimmer",
-		"path": "/home/repos/blink-frost-dune-glimmer",
-		"lastModified": "2024-01-01T00:00:00Z",
-		"synced": false,
-		"syncDetails": [
-			"uncommitted changes",
-			"untracked branch(es)"
-		],
-		"author": "Test Author"
-	},
-	{
-		"name": "stone-drift-moon-sparkle-breeze",
-		"path": "/home/repos/stone-drift-moon-sparkle-breeze",
-		"lastModified": "2024-01-02T00:00:00Z",
-		"synced": false,
-		"syncDetails": [
-			"uncommitted changes",
-			"untracked branch(es)",
-			"branch(es) ahead"
-		],
-		"author": "Test Author"
-	}
-]
-`
-
-	keyToOutputs := map[string]string{
-		"short": outWithShortFields,
-		"long":  outWithLongFields,
-	}
-	return keyToOutputs[key]
-}
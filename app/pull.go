@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+)
+
+// the outcomes of pulling a repo in the order they are summarized
+var PullStatuses = []string{PullUpdated, PullWouldUpdate, PullUpToDate, PullSkippedDirty, PullNoUpstream, PullDiverged, PullFailed}
+
+// the outcomes of pulling a repo
+const (
+	PullUpdated      = "updated"
+	PullWouldUpdate  = "would-update"
+	PullUpToDate     = "up-to-date"
+	PullSkippedDirty = "skipped-dirty"
+	PullNoUpstream   = "no-upstream"
+	PullDiverged     = "diverged"
+	PullFailed       = "failed"
+)
+
+// fast-forwards the checked out branch of repo to its upstream branch. The
+// repo is only changed when it has no uncommitted changes and the branch is
+// behind but not ahead of its upstream, so a merge or rebase is never needed.
+// The ahead and behind counts of repo are used, so the upstream branch should
+// have been fetched when repo was found. When dryRun is true, the repo is
+// not changed and repos that would be updated are reported as would-update
+func PullRepo(repo Repo, dryRun bool) Result {
+	result := Result{Repo: repo, Branch: repo.Branch}
+	switch {
+	case repo.Branch == "":
+		result.Status, result.Detail = PullNoUpstream, "detached HEAD"
+		return result
+	case repo.UncommittedFiles > 0:
+		result.Status = PullSkippedDirty
+		result.Detail = fmt.Sprintf("%v uncommitted file(s)", repo.UncommittedFiles)
+		return result
+	}
+	err := gitCommand(repo.AbsPath, "rev-parse", "--abbrev-ref", "@{upstream}")
+	if err != nil {
+		result.Status, result.Detail = PullNoUpstream, repo.Branch+" has no upstream branch"
+		return result
+	}
+	switch {
+	case repo.Ahead > 0 && repo.Behind > 0:
+		result.Status = PullDiverged
+		result.Detail = fmt.Sprintf("%v ahead, %v behind", repo.Ahead, repo.Behind)
+	case repo.Behind == 0:
+		result.Status = PullUpToDate
+	case dryRun:
+		result.Status = PullWouldUpdate
+		result.Detail = fmt.Sprintf("%v commit(s) behind", repo.Behind)
+	default:
+		// --ff-only makes git refuse instead of merging if the branch
+		// changed since the repo was checked
+		err = gitCommand(repo.AbsPath, "merge", "--ff-only", "-q", "@{upstream}")
+		if err != nil {
+			result.Status, result.Detail = PullFailed, err.Error()
+			return result
+		}
+		result.Status = PullUpdated
+		result.Detail = fmt.Sprintf("%v commit(s)", repo.Behind)
+	}
+	return result
+}
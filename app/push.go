@@ -0,0 +1,112 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// the outcomes of pushing a branch in the order they are summarized
+var PushStatuses = []string{PushPushed, PushWouldPush, PushUpToDate, PushNoUpstream, PushBehind, PushDiverged, PushFailed}
+
+// the outcomes of pushing a branch
+const (
+	PushPushed     = "pushed"
+	PushWouldPush  = "would-push"
+	PushUpToDate   = "up-to-date"
+	PushNoUpstream = "no-upstream"
+	PushBehind     = "behind"
+	PushDiverged   = "diverged"
+	PushFailed     = "failed"
+)
+
+// a local branch and how it would be pushed. Status is would-push for
+// branches that Push pushes and the reason the branch is skipped otherwise
+type PushPlan struct {
+	Result
+	remote string
+	// the branch on the remote that is pushed to
+	remoteBranch string
+	setUpstream  bool
+}
+
+// returns a plan for each local branch of repo. Branches that are strictly
+// ahead of their upstream branch are pushed. When setUpstream is true,
+// branches without an upstream branch are pushed to a branch with the same
+// name on the origin remote, or the first remote if there is no origin, and
+// set as the upstream branch
+func PlanPush(repo Repo, setUpstream bool) ([]PushPlan, error) {
+	// tab separated fields for each local branch, the track field is in the
+	// form "ahead 1, behind 2"
+	out, err := gitOutput(repo.AbsPath, "for-each-ref",
+		"--format=%(refname:short)%09%(upstream:remotename)%09%(upstream:remoteref)%09%(upstream:track,nobracket)",
+		"refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	defaultRemote, err := getRemoteName(repo.AbsPath)
+	if err != nil {
+		return nil, err
+	}
+	var plans []PushPlan
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected output from git for-each-ref: %v", line)
+		}
+		branch, remote, remoteRef, track := fields[0], fields[1], fields[2], fields[3]
+		plan := PushPlan{Result: Result{Repo: repo, Branch: branch}}
+		ahead, behind := parseTrack(track)
+		switch {
+		case remote == "" && setUpstream && defaultRemote != "":
+			plan.Status = PushWouldPush
+			plan.Detail = fmt.Sprintf("set upstream to %v/%v", defaultRemote, branch)
+			plan.remote, plan.remoteBranch, plan.setUpstream = defaultRemote, branch, true
+		case remote == "":
+			plan.Status = PushNoUpstream
+			if setUpstream {
+				plan.Detail = "repo has no remote"
+			}
+		case track == "gone":
+			plan.Status, plan.Detail = PushNoUpstream, "upstream branch is gone"
+		case ahead > 0 && behind > 0:
+			plan.Status = PushDiverged
+			plan.Detail = fmt.Sprintf("%v ahead, %v behind", ahead, behind)
+		case behind > 0:
+			plan.Status = PushBehind
+			plan.Detail = fmt.Sprintf("%v commit(s) behind", behind)
+		case ahead == 0:
+			plan.Status = PushUpToDate
+		default:
+			plan.Status = PushWouldPush
+			plan.Detail = fmt.Sprintf("%v commit(s) to %v", ahead, remote)
+			plan.remote = remote
+			plan.remoteBranch = strings.TrimPrefix(remoteRef, "refs/heads/")
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// pushes the branch of plan if its status is would-push and returns the
+// outcome. git refuses to push anything that is not a fast-forward
+func Push(plan PushPlan) Result {
+	result := plan.Result
+	if plan.Status != PushWouldPush {
+		return result
+	}
+	args := []string{"push", "-q"}
+	if plan.setUpstream {
+		args = append(args, "--set-upstream")
+	}
+	args = append(args, plan.remote, "refs/heads/"+plan.Branch+":refs/heads/"+plan.remoteBranch)
+	err := gitCommand(plan.Repo.AbsPath, args...)
+	if err != nil {
+		result.Status, result.Detail = PushFailed, err.Error()
+		return result
+	}
+	result.Status = PushPushed
+	return result
+}
@@ -1,127 +1,245 @@
 package app
 
 import (
+	"cmp"
 	"fmt"
-	"reflect"
+	"regexp"
 	"slices"
 	"strings"
 	"time"
 )
 
-type validator interface {
-	validate() error
+// Query is a filter or a sort that can be applied to repos. Queries are added
+// to a Registry which validates and applies them in the order they were added
+type Query interface {
+	// Validate returns an error if the query cannot be applied, such as
+	// when the value for the query is invalid
+	Validate() error
+	// Apply mutates repos by filtering or reordering them
+	Apply(repos *[]Repo) error
 }
 
-type executor interface {
-	apply(*[]Repo) error
+// Filter is a Query that keeps only the repos it matches. Filters can be
+// checked against a single repo at a time without needing the other repos
+type Filter interface {
+	Query
+	Match(repo Repo) bool
 }
 
-// this interface is needed to get the value for each query in the
-// validateQueries and applyQueries functions, making the access of the query's
-// value field value simpler
-type query interface {
-	value() string
+// Registry holds an ordered list of queries to apply to repos
+type Registry struct {
+	queries []Query
 }
 
-type queries struct {
-	// place sort at the end as there will be less elements to sort in the
-	// slice after filtering
-	LastModified lastModifiedFilter
-	Synced       syncedFilter
-	Author       authorFilter
-	Sort         sorter
+// returns an empty Registry. Queries are added to it with Add
+func NewRegistry() *Registry {
+	return &Registry{}
 }
 
-type sortFunc func([]Repo)
+// adds queries to the end of the registry. Filters should generally be added
+// before sorts as there will be less elements to sort after filtering
+func (r *Registry) Add(queries ...Query) {
+	r.queries = append(r.queries, queries...)
+}
+
+// returns the queries in the registry in the order they will be applied
+func (r *Registry) Queries() []Query {
+	return slices.Clone(r.queries)
+}
+
+// validates every query in the registry and returns the first error found
+func (r *Registry) Validate() error {
+	for _, query := range r.queries {
+		err := query.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applies every query in the registry to repos in order. All queries must be
+// validated through Validate before applying
+func (r *Registry) Apply(repos *[]Repo) error {
+	for _, query := range r.queries {
+		err := query.Apply(repos)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// returns true if repo matches every filter in the registry. Queries that are
+// not filters, such as sorts, are ignored
+func (r *Registry) Match(repo Repo) bool {
+	for _, query := range r.queries {
+		filter, ok := query.(Filter)
+		if ok && !filter.Match(repo) {
+			return false
+		}
+	}
+	return true
+}
+
+// keeps only the repos that match
+func filterRepos(repos *[]Repo, match func(Repo) bool) {
+	var filteredRepos []Repo
+	for _, repo := range *repos {
+		if match(repo) {
+			filteredRepos = append(filteredRepos, repo)
+		}
+	}
+	*repos = filteredRepos
+}
+
+// FilterFunc is a Filter that keeps the repos for which the function returns
+// true. It can be used to add custom filters to a Registry
+type FilterFunc func(repo Repo) bool
+
+func (f FilterFunc) Validate() error {
+	return nil
+}
+
+func (f FilterFunc) Apply(repos *[]Repo) error {
+	filterRepos(repos, f)
+	return nil
+}
+
+func (f FilterFunc) Match(repo Repo) bool {
+	return f(repo)
+}
+
+// SortFunc is a Query that stable sorts repos using the function to compare
+// two repos in the same way as slices.SortStableFunc. It can be used to add
+// custom sorts to a Registry
+type SortFunc func(a, b Repo) int
+
+func (s SortFunc) Validate() error {
+	return nil
+}
+
+func (s SortFunc) Apply(repos *[]Repo) error {
+	slices.SortStableFunc(*repos, s)
+	return nil
+}
 
 // sorts in alphabetical order ascending
-func sortByName(repos []Repo) {
-	slices.SortStableFunc(repos, func(a, b Repo) int {
-		return strings.Compare(a.Name, b.Name)
-	})
+func sortByName(a, b Repo) int {
+	return strings.Compare(a.Name, b.Name)
 }
 
 // sorts in alphabetical order ascending
-func sortByPath(repos []Repo) {
-	slices.SortStableFunc(repos, func(a, b Repo) int {
-		return strings.Compare(a.AbsPath, b.AbsPath)
-	})
+func sortByPath(a, b Repo) int {
+	return strings.Compare(a.AbsPath, b.AbsPath)
 }
 
 // sorts in order of last modified datetime ascending
-func sortByLastModified(repos []Repo) {
-	slices.SortStableFunc(repos, func(a, b Repo) int {
-		return a.LastModified.Compare(b.LastModified)
-	})
+func sortByLastModified(a, b Repo) int {
+	return a.LastModified.Compare(b.LastModified)
 }
 
 // sorts false values first and then true values as it is likely user
 // will want to see repos that are not synced first
-func sortBySyncStatus(repos []Repo) {
-	slices.SortStableFunc(repos, func(a, b Repo) int {
-		if a.SyncedWithRemote && !b.SyncedWithRemote {
-			return 1
-		} else if !a.SyncedWithRemote && b.SyncedWithRemote {
-			return -1
-		} else {
-			return 0
-		}
-	})
+func sortBySyncStatus(a, b Repo) int {
+	if a.SyncedWithRemote && !b.SyncedWithRemote {
+		return 1
+	} else if !a.SyncedWithRemote && b.SyncedWithRemote {
+		return -1
+	} else {
+		return 0
+	}
 }
 
 // sorts in alphabetical order ascending
-func sortByAuthor(repos []Repo) {
-	slices.SortStableFunc(repos, func(a, b Repo) int {
-		return strings.Compare(
-			// compare lower case to make sort case insensitive
-			// because author names will be a mix of capitalized
-			// and non-capitalized
-			strings.ToLower(a.Author), strings.ToLower(b.Author))
-	})
+func sortByAuthor(a, b Repo) int {
+	return strings.Compare(
+		// compare lower case to make sort case insensitive
+		// because author names will be a mix of capitalized
+		// and non-capitalized
+		strings.ToLower(a.Author), strings.ToLower(b.Author))
+}
+
+// sorts in order of size on disk ascending
+func sortBySize(a, b Repo) int {
+	return cmp.Compare(a.Size, b.Size)
+}
+
+// add possible sort flag values and their corresponding sort functions here
+var sortOptions = map[string]SortFunc{
+	"name":         sortByName,
+	"path":         sortByPath,
+	"lastmodified": sortByLastModified,
+	"synced":       sortBySyncStatus,
+	"author":       sortByAuthor,
+	"size":         sortBySize,
+}
+
+// returns the names of the sort options accepted by NewSorter in
+// alphabetical order
+func SortOptions() []string {
+	var options []string
+	for key := range sortOptions {
+		options = append(options, key)
+	}
+	slices.Sort(options)
+	return options
 }
 
 type sorter struct {
-	Value        string
-	validOptions map[string]sortFunc
+	value string
 }
 
-func (s sorter) value() string {
-	return s.Value
+// returns a Query that sorts repos by one of the keys in SortOptions
+func NewSorter(value string) Query {
+	return sorter{value: value}
 }
 
-func (s sorter) validate() error {
-	_, ok := s.validOptions[strings.ToLower(s.Value)]
+func (s sorter) Validate() error {
+	_, ok := sortOptions[strings.ToLower(s.value)]
 	if !ok {
-		var validOptions []string
-		for key := range s.validOptions {
-			validOptions = append(validOptions, key)
-		}
-		// sort the keys to get a deterministic error message
-		slices.SortFunc(validOptions, func(a, b string) int {
-			return strings.Compare(a, b)
-		})
-		return fmt.Errorf("%v is not a valid sort option. Options: %v", s.Value, strings.Join(validOptions, " | "))
+		return fmt.Errorf("%v is not a valid sort option. Options: %v", s.value, strings.Join(SortOptions(), " | "))
 	}
 	return nil
 }
 
-func (s sorter) apply(repos *[]Repo) error {
+func (s sorter) Apply(repos *[]Repo) error {
 	// select the appropriate sort function based on flag value
-	sort := s.validOptions[strings.ToLower(s.Value)]
-	sort(*repos)
+	sort := sortOptions[strings.ToLower(s.value)]
+	return sort.Apply(repos)
+}
+
+type reverser struct{}
+
+// returns a Query that reverses the order of repos. It is usually added after
+// a sort to sort the repos in descending order
+func NewReverser() Query {
+	return reverser{}
+}
+
+func (r reverser) Validate() error {
+	return nil
+}
+
+func (r reverser) Apply(repos *[]Repo) error {
+	for i, j := 0, len(*repos)-1; i < j; i, j = i+1, j-1 {
+		(*repos)[i], (*repos)[j] = (*repos)[j], (*repos)[i]
+	}
 	return nil
 }
 
 type syncedFilter struct {
-	Value string
+	value string
 }
 
-func (s syncedFilter) value() string {
-	return s.Value
+// returns a Filter that keeps repos that are synced when value is 'yes' or
+// 'y' and repos that are not synced when value is 'no' or 'n'
+func NewSyncedFilter(value string) Filter {
+	return syncedFilter{value: value}
 }
 
-func (s syncedFilter) validate() error {
-	value := strings.ToLower(s.Value)
+func (s syncedFilter) Validate() error {
+	value := strings.ToLower(s.value)
 	if value != "yes" &&
 		value != "y" &&
 		value != "no" &&
@@ -131,50 +249,53 @@ func (s syncedFilter) validate() error {
 	return nil
 }
 
-func (s syncedFilter) apply(repos *[]Repo) error {
-	value := strings.ToLower(s.Value)
-	var queryBool bool
-	if value == "yes" || value == "y" {
-		queryBool = true
-	} else {
-		// no need to check for no explicitly as it is already covered
-		// by validate() method
-		queryBool = false
-	}
-	var filteredRepos []Repo
-	for _, repo := range *repos {
-		if repo.SyncedWithRemote == queryBool {
-			filteredRepos = append(filteredRepos, repo)
-		}
-	}
-	*repos = filteredRepos
+func (s syncedFilter) Apply(repos *[]Repo) error {
+	filterRepos(repos, s.Match)
 	return nil
 }
 
+func (s syncedFilter) Match(repo Repo) bool {
+	value := strings.ToLower(s.value)
+	// no need to check for no explicitly as it is already covered
+	// by Validate() method
+	queryBool := value == "yes" || value == "y"
+	return repo.SyncedWithRemote == queryBool
+}
+
 type lastModifiedFilter struct {
-	Value string
+	value string
+	// time zone the dates are compared in. The time zone of the last
+	// modified time of each repo is used when nil
+	location *time.Location
 }
 
-func (s lastModifiedFilter) value() string {
-	return s.Value
+// returns a Filter that keeps repos last modified on a date in the format
+// yyyy-mm-dd. The date can be prefixed with '<=', '>=', '<' or '>' to keep
+// repos modified before or after the date
+func NewLastModifiedFilter(value string) Filter {
+	return lastModifiedFilter{value: value}
 }
 
-func (l lastModifiedFilter) validate() error {
-	var dateString string
+// same as NewLastModifiedFilter but the day a repo was last modified is the
+// day in loc so that the same repos match regardless of the local time zone
+func NewLastModifiedFilterIn(value string, loc *time.Location) Filter {
+	return lastModifiedFilter{value: value, location: loc}
+}
+
+// splits the value into the comparison operator and the date string
+func (l lastModifiedFilter) parse() (string, string) {
 	// check for each prefix before trimming because some prefixes include
 	// other prefixes. ex: <= includes <
-	switch {
-	case strings.HasPrefix(l.Value, "<="):
-		dateString = strings.TrimPrefix(l.Value, "<=")
-	case strings.HasPrefix(l.Value, ">="):
-		dateString = strings.TrimPrefix(l.Value, ">=")
-	case strings.HasPrefix(l.Value, "<"):
-		dateString = strings.TrimPrefix(l.Value, "<")
-	case strings.HasPrefix(l.Value, ">"):
-		dateString = strings.TrimPrefix(l.Value, ">")
-	default:
-		dateString = l.Value
+	for _, operator := range []string{"<=", ">=", "<", ">"} {
+		if strings.HasPrefix(l.value, operator) {
+			return operator, strings.TrimPrefix(l.value, operator)
+		}
 	}
+	return "=", l.value
+}
+
+func (l lastModifiedFilter) Validate() error {
+	_, dateString := l.parse()
 	_, err := time.Parse(time.DateOnly, dateString)
 	if err != nil {
 		return fmt.Errorf("unexpected date %v, date must be in the format yyyy-mm-dd and can only be prefixed with '<=', '>=', '<' or '>'", dateString)
@@ -182,150 +303,191 @@ func (l lastModifiedFilter) validate() error {
 	return nil
 }
 
-func (l lastModifiedFilter) apply(repos *[]Repo) error {
-	var dateString string
-	var queryDate time.Time
-	var filteredRepos []Repo
-	switch {
-	case strings.HasPrefix(l.Value, "<="):
-		dateString = strings.TrimPrefix(l.Value, "<=")
-		queryDate, _ = time.Parse(time.DateOnly, dateString)
-		for _, repo := range *repos {
-			// compare string representations of date to exclude time in comparison
-			if repo.LastModified.Format(time.DateOnly) <= queryDate.Format(time.DateOnly) {
-				filteredRepos = append(filteredRepos, repo)
-			}
-		}
-	case strings.HasPrefix(l.Value, ">="):
-		dateString = strings.TrimPrefix(l.Value, ">=")
-		queryDate, _ = time.Parse(time.DateOnly, dateString)
-		for _, repo := range *repos {
-			// compare string representations of date to exclude time in comparison
-			if repo.LastModified.Format(time.DateOnly) >= queryDate.Format(time.DateOnly) {
-				filteredRepos = append(filteredRepos, repo)
-			}
-		}
-	case strings.HasPrefix(l.Value, "<"):
-		dateString = strings.TrimPrefix(l.Value, "<")
-		queryDate, _ = time.Parse(time.DateOnly, dateString)
-		for _, repo := range *repos {
-			// compare string representations of date to exclude time in comparison
-			if repo.LastModified.Format(time.DateOnly) < queryDate.Format(time.DateOnly) {
-				filteredRepos = append(filteredRepos, repo)
-			}
-		}
-	case strings.HasPrefix(l.Value, ">"):
-		dateString = strings.TrimPrefix(l.Value, ">")
-		queryDate, _ = time.Parse(time.DateOnly, dateString)
-		for _, repo := range *repos {
-			// compare string representations of date to exclude time in comparison
-			if repo.LastModified.Format(time.DateOnly) > queryDate.Format(time.DateOnly) {
-				filteredRepos = append(filteredRepos, repo)
-			}
-		}
-	default:
-		dateString = l.Value
-		queryDate, _ = time.Parse(time.DateOnly, dateString)
-		for _, repo := range *repos {
-			// compare string representations of date to exclude time in comparison
-			if repo.LastModified.Format(time.DateOnly) == queryDate.Format(time.DateOnly) {
-				filteredRepos = append(filteredRepos, repo)
-			}
-		}
+func (l lastModifiedFilter) Apply(repos *[]Repo) error {
+	filterRepos(repos, l.Match)
+	return nil
+}
 
+func (l lastModifiedFilter) Match(repo Repo) bool {
+	operator, dateString := l.parse()
+	queryDate, _ := time.Parse(time.DateOnly, dateString)
+	lastModified := repo.LastModified
+	if l.location != nil {
+		lastModified = lastModified.In(l.location)
+	}
+	// compare string representations of date to exclude time in comparison
+	repoDate := lastModified.Format(time.DateOnly)
+	query := queryDate.Format(time.DateOnly)
+	switch operator {
+	case "<=":
+		return repoDate <= query
+	case ">=":
+		return repoDate >= query
+	case "<":
+		return repoDate < query
+	case ">":
+		return repoDate > query
+	default:
+		return repoDate == query
 	}
-	*repos = filteredRepos
-	return nil
 }
 
+// the ways an author filter value can be matched against the author name and
+// email of a repo
+var authorMatchModes = []string{"substring", "exact", "regex"}
+
 type authorFilter struct {
-	Value string
+	values []string
+	mode   string
+	// resolves the value 'me' to the current user
+	identity func() (GitIdentity, error)
+	// set by Validate
+	patterns []*regexp.Regexp
+	me       GitIdentity
 }
 
-func (s authorFilter) value() string {
-	return s.Value
+// returns a Filter that keeps repos where the name or email of the author of
+// the last commit matches any of values, ignoring case. mode is one of
+// 'substring', 'exact' or 'regex' and defaults to 'substring' when empty.
+// The value 'me' matches the user.name or user.email set in git config
+func NewAuthorFilter(mode string, values ...string) Filter {
+	return &authorFilter{
+		values: values,
+		mode:   mode,
+		identity: func() (GitIdentity, error) {
+			return CurrentGitIdentity("")
+		},
+	}
 }
 
-func (s authorFilter) validate() error {
-	// all querues must be validated through ValidateQueries() before applying
-	// hence return nil to indicate any value for author is valid
+func (a *authorFilter) Validate() error {
+	if a.mode == "" {
+		a.mode = "substring"
+	}
+	if !slices.Contains(authorMatchModes, a.mode) {
+		return fmt.Errorf("%v is not a valid author match option. Options: %v", a.mode, strings.Join(authorMatchModes, " | "))
+	}
+	a.patterns = nil
+	for _, value := range a.values {
+		if strings.ToLower(value) == "me" {
+			me, err := a.identity()
+			if err != nil {
+				return fmt.Errorf("unable to find the author for 'me': %v", err)
+			}
+			a.me = me
+			continue
+		}
+		if a.mode == "regex" {
+			// compile case insensitive to match the other modes
+			pattern, err := regexp.Compile("(?i)" + value)
+			if err != nil {
+				return fmt.Errorf("invalid author regex %v: %v", value, err)
+			}
+			a.patterns = append(a.patterns, pattern)
+		}
+	}
 	return nil
 }
 
-func (s authorFilter) apply(repos *[]Repo) error {
-	var filteredRepos []Repo
-	for _, repo := range *repos {
-		// case insensitive check
-		if strings.Contains(strings.ToLower(repo.Author), strings.ToLower(s.value())) {
-			filteredRepos = append(filteredRepos, repo)
-		}
-	}
-	*repos = filteredRepos
+func (a *authorFilter) Apply(repos *[]Repo) error {
+	filterRepos(repos, a.Match)
 	return nil
 }
 
-// returns a pointer to queries struct and is used to set the filters and sort
-// for repos
-func NewQueries() *queries {
-	return &queries{Sort: sorter{validOptions: map[string]sortFunc{
-		// add possible sort flag values and their corresponding sort functions here
-		"name":         sortByName,
-		"path":         sortByPath,
-		"lastmodified": sortByLastModified,
-		"synced":       sortBySyncStatus,
-		"author":       sortByAuthor,
-	}}}
-}
-
-func ValidateQueries(q *queries) error {
-	var err error
-	v := reflect.ValueOf(*q)
-	// loop through the fields in Queries and execute the validate method
-	// for each query that has been set, meaning the flag was used by the
-	// user
-	for i := 0; i < v.NumField(); i++ {
-		query := v.Field(i).Interface().(query)
-		// ignore queries where the value has not been set
-		// indicating that the flag for the query was not used
-		if query.value() == "" {
+func (a *authorFilter) Match(repo Repo) bool {
+	author := strings.ToLower(repo.Author)
+	email := strings.ToLower(repo.AuthorEmail)
+	// multiple values are combined with OR
+	for _, value := range a.values {
+		value = strings.ToLower(value)
+		if value == "me" {
+			// an unset name or email should not match repos where the
+			// author name or email is also empty
+			if (a.me.Name != "" && strings.EqualFold(a.me.Name, repo.Author)) ||
+				(a.me.Email != "" && strings.EqualFold(a.me.Email, repo.AuthorEmail)) {
+				return true
+			}
 			continue
 		}
-		validator := v.Field(i).Interface().(validator)
-		err = validator.validate()
-		if err != nil {
-			return err
+		switch a.mode {
+		case "exact":
+			if author == value || email == value {
+				return true
+			}
+		case "regex":
+			// regex values are checked against the compiled patterns below
+		default:
+			if strings.Contains(author, value) || strings.Contains(email, value) {
+				return true
+			}
 		}
 	}
-	return nil
+	for _, pattern := range a.patterns {
+		if pattern.MatchString(repo.Author) || pattern.MatchString(repo.AuthorEmail) {
+			return true
+		}
+	}
+	return false
 }
 
-func ApplyQueries(q *queries, repos *[]Repo) error {
-	var err error
-	v := reflect.ValueOf(*q)
-	// loop through the fields in Queries and execute the apply method
-	// for each query that has been set, meaning the flag was used by the
-	// user
-	for i := 0; i < v.NumField(); i++ {
-		query := v.Field(i).Interface().(query)
-		// ignore queries where the value has not been set
-		// indicating that the flag for the query was not used
-		if query.value() == "" {
-			continue
-		}
-		executor := v.Field(i).Interface().(executor)
-		err = executor.apply(repos)
-		if err != nil {
-			return err
+// the sort key and sort direction used by each --top preset
+type topPreset struct {
+	sort    string
+	reverse bool
+}
+
+var topPresets = map[string]topPreset{
+	// least recently modified repos first
+	"stale": {sort: "lastmodified", reverse: false},
+	// most recently modified repos first
+	"busy": {sort: "lastmodified", reverse: true},
+	// repos taking up the most space on disk first
+	"largest": {sort: "size", reverse: true},
+}
+
+func ValidateTop(value string) error {
+	_, ok := topPresets[strings.ToLower(value)]
+	if !ok {
+		var validOptions []string
+		for key := range topPresets {
+			validOptions = append(validOptions, key)
 		}
+		// sort the keys to get a deterministic error message
+		slices.Sort(validOptions)
+		return fmt.Errorf("%v is not a valid top option. Options: %v", value, strings.Join(validOptions, " | "))
 	}
 	return nil
 }
 
-func ReverseSort(repos *[]Repo) error {
-	// handle reverse sort separately from queries since the reverse flag is a
-	// boolean and does not implement logic for flag values and validate method
-	for i, j := 0, len(*repos)-1; i < j; i, j = i+1, j-1 {
-		(*repos)[i], (*repos)[j] = (*repos)[j], (*repos)[i]
+// returns the sort option and whether the sort should be reversed for a top
+// preset. value must be validated with ValidateTop first
+func TopPreset(value string) (string, bool) {
+	preset := topPresets[strings.ToLower(value)]
+	return preset.sort, preset.reverse
+}
+
+func ValidatePagination(offset int, limit int) error {
+	if offset < 0 {
+		return fmt.Errorf("invalid offset %v, offset cannot be negative", offset)
+	}
+	if limit < 0 {
+		return fmt.Errorf("invalid limit %v, limit cannot be negative", limit)
 	}
 	return nil
 }
+
+// keeps the repos from offset up to limit repos. offset and limit must be
+// validated with ValidatePagination first
+func Paginate(repos *[]Repo, offset int, limit int) {
+	// handle pagination separately from queries since the repos that matched
+	// the queries are still needed for the summary after paginating
+	// a limit of 0 means that all repos after offset are kept
+	if offset > len(*repos) {
+		offset = len(*repos)
+	}
+	end := len(*repos)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	*repos = (*repos)[offset:end]
+}
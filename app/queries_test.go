@@ -3,6 +3,7 @@ package app
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -15,8 +16,6 @@ var (
 	jan4, _  = time.Parse(time.DateOnly, "2024-01-04")
 )
 
-var testQueries = NewQueries()
-
 var sortTests = []struct {
 	key  string
 	want []Repo
@@ -47,9 +46,8 @@ func TestSort(t *testing.T) {
 	for _, test := range sortTests {
 		testname := fmt.Sprintf("%v", test.key)
 		t.Run(testname, func(t *testing.T) {
-			testQueries.Sort.Value = test.key
 			repos := getInputRepos()
-			err := testQueries.Sort.apply(&repos)
+			err := NewSorter(test.key).Apply(&repos)
 			// the apply function  mutates the input hence the input itself is compared with want
 			if !reflect.DeepEqual(repos, test.want) || err != nil {
 				t.Errorf(
@@ -63,9 +61,8 @@ func TestSort(t *testing.T) {
 }
 
 func TestSortError(t *testing.T) {
-	wantE := fmt.Errorf("invalid is not a valid sort option. Options: author | lastmodified | name | path | synced")
-	testQueries.Sort.Value = "invalid"
-	gotE := testQueries.Sort.validate()
+	wantE := fmt.Errorf("invalid is not a valid sort option. Options: author | lastmodified | name | path | size | synced")
+	gotE := NewSorter("invalid").Validate()
 	if gotE == nil || gotE.Error() != wantE.Error() {
 		t.Errorf(
 			"got (%v)\nwant (%v)",
@@ -83,10 +80,9 @@ func TestReverseSort(t *testing.T) {
 	// not care for the fields in each Repo
 	// so ensure the input is first sorted by lastmodified before reversing
 	// it. Then the reverse sort will sort the input by lastmodified descending
-	testQueries.Sort.Value = "lastmodified"
-	testQueries.Sort.apply(&repos)
-	ReverseSort(&repos)
-	// reverseSort function  mutates the input hence the input itself is compared with want
+	NewSorter("lastmodified").Apply(&repos)
+	NewReverser().Apply(&repos)
+	// the apply function  mutates the input hence the input itself is compared with want
 	if !reflect.DeepEqual(repos, want) {
 		t.Errorf(
 			"got (%v)\nwant (%v)",
@@ -96,6 +92,83 @@ func TestReverseSort(t *testing.T) {
 	}
 }
 
+var paginateTests = []struct {
+	offset int
+	limit  int
+	want   []string
+}{
+	{0, 0, []string{"e", "b", "c", "d", "a"}},
+	{0, 2, []string{"e", "b"}},
+	{2, 0, []string{"c", "d", "a"}},
+	{2, 2, []string{"c", "d"}},
+	{4, 5, []string{"a"}},
+	{7, 2, []string{}},
+}
+
+func TestPaginate(t *testing.T) {
+	for _, test := range paginateTests {
+		testname := fmt.Sprintf("offset %v limit %v", test.offset, test.limit)
+		t.Run(testname, func(t *testing.T) {
+			repos := getInputRepos()
+			Paginate(&repos, test.offset, test.limit)
+			got := []string{}
+			for _, repo := range repos {
+				got = append(got, repo.Name)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v\nwant %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidatePaginationError(t *testing.T) {
+	wantE := fmt.Errorf("invalid limit -1, limit cannot be negative")
+	gotE := ValidatePagination(0, -1)
+	if gotE == nil || gotE.Error() != wantE.Error() {
+		t.Errorf(
+			"got (%v)\nwant (%v)",
+			gotE, wantE,
+		)
+	}
+}
+
+func TestTopPreset(t *testing.T) {
+	var tests = []struct {
+		top         string
+		wantSort    string
+		wantReverse bool
+	}{
+		{"stale", "lastmodified", false},
+		{"busy", "lastmodified", true},
+		{"Largest", "size", true},
+	}
+	for _, test := range tests {
+		t.Run(test.top, func(t *testing.T) {
+			err := ValidateTop(test.top)
+			gotSort, gotReverse := TopPreset(test.top)
+			if gotSort != test.wantSort || gotReverse != test.wantReverse || err != nil {
+				t.Errorf(
+					"got (%v, %v, %v)\nwant (%v, %v, %v)",
+					gotSort, gotReverse, err,
+					test.wantSort, test.wantReverse, nil,
+				)
+			}
+		})
+	}
+}
+
+func TestTopPresetError(t *testing.T) {
+	wantE := fmt.Errorf("invalid is not a valid top option. Options: busy | largest | stale")
+	gotE := ValidateTop("invalid")
+	if gotE == nil || gotE.Error() != wantE.Error() {
+		t.Errorf(
+			"got (%v)\nwant (%v)",
+			gotE, wantE,
+		)
+	}
+}
+
 var syncedFilterTests = []struct {
 	key  string
 	want []Repo
@@ -122,9 +195,8 @@ func TestSyncedFilter(t *testing.T) {
 	for _, test := range syncedFilterTests {
 		testname := fmt.Sprintf("%v", test.key)
 		t.Run(testname, func(t *testing.T) {
-			testQueries.Synced.Value = test.key
 			repos := getInputRepos()
-			err := testQueries.Synced.apply(&repos)
+			err := NewSyncedFilter(test.key).Apply(&repos)
 			// the apply function  mutates the input hence the input itself is compared with want
 			if !reflect.DeepEqual(repos, test.want) || err != nil {
 				t.Errorf(
@@ -139,8 +211,7 @@ func TestSyncedFilter(t *testing.T) {
 
 func TestSyncedFilterError(t *testing.T) {
 	wantE := fmt.Errorf("incorrect value for synced, value must be either 'yes', 'y', 'no' or 'n'")
-	testQueries.Synced.Value = "invalid"
-	gotE := testQueries.Synced.validate()
+	gotE := NewSyncedFilter("invalid").Validate()
 	if gotE == nil || gotE.Error() != wantE.Error() {
 		t.Errorf(
 			"got (%v)\nwant (%v)",
@@ -179,9 +250,8 @@ func TestLastModifiedFilter(t *testing.T) {
 	for _, test := range lastmodifiedFilterTests {
 		testname := fmt.Sprintf("%v", test.key)
 		t.Run(testname, func(t *testing.T) {
-			testQueries.LastModified.Value = test.key
 			repos := getInputRepos()
-			err := testQueries.LastModified.apply(&repos)
+			err := NewLastModifiedFilter(test.key).Apply(&repos)
 			// the apply function  mutates the input hence the input itself is compared with want
 			if !reflect.DeepEqual(repos, test.want) || err != nil {
 				t.Errorf(
@@ -196,8 +266,7 @@ func TestLastModifiedFilter(t *testing.T) {
 
 func TestLastModifiedFilterError(t *testing.T) {
 	wantE := fmt.Errorf("unexpected date invalid, date must be in the format yyyy-mm-dd and can only be prefixed with '<=', '>=', '<' or '>'")
-	testQueries.LastModified.Value = "invalid"
-	gotE := testQueries.LastModified.validate()
+	gotE := NewLastModifiedFilter("invalid").Validate()
 	if gotE == nil || gotE.Error() != wantE.Error() {
 		t.Errorf(
 			"got (%v)\nwant (%v)",
@@ -206,6 +275,21 @@ func TestLastModifiedFilterError(t *testing.T) {
 	}
 }
 
+func TestLastModifiedFilterIn(t *testing.T) {
+	// 23:00 in UTC is already the next day in Tokyo
+	repo := Repo{Name: "a", LastModified: time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC)}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	if !NewLastModifiedFilterIn("2024-01-01", time.UTC).Match(repo) {
+		t.Errorf("repo does not match 2024-01-01 in UTC")
+	}
+	if !NewLastModifiedFilterIn("2024-01-02", tokyo).Match(repo) {
+		t.Errorf("repo does not match 2024-01-02 in Asia/Tokyo")
+	}
+}
+
 var authorFilterTests = []struct {
 	key  string
 	want []Repo
@@ -232,9 +316,8 @@ func TestAuthorFilter(t *testing.T) {
 	for _, test := range authorFilterTests {
 		testname := fmt.Sprintf("%v", test.key)
 		t.Run(testname, func(t *testing.T) {
-			testQueries.Author.Value = test.key
 			repos := getInputRepos()
-			err := testQueries.Author.apply(&repos)
+			err := NewAuthorFilter("", test.key).Apply(&repos)
 			// the apply function  mutates the input hence the input itself is compared with want
 			if !reflect.DeepEqual(repos, test.want) || err != nil {
 				t.Errorf(
@@ -247,25 +330,106 @@ func TestAuthorFilter(t *testing.T) {
 	}
 }
 
-func TestValidateQueries(t *testing.T) {
-	emptyQueries := NewQueries()
-	validQueries := NewQueries()
-	validQueries.Sort.Value = "name"
-	validQueries.Synced.Value = "y"
-	validQueries.LastModified.Value = ">=2024-01-01"
-	emptyAndValidQueries := NewQueries()
-	emptyAndValidQueries.Synced.Value = "no"
+func TestAuthorFilterModes(t *testing.T) {
+	repos := []Repo{
+		{Name: "alice", Author: "Alice", AuthorEmail: "alice@example.com"},
+		{Name: "alan", Author: "Alan", AuthorEmail: "alan@example.com"},
+		{Name: "sal", Author: "Sal", AuthorEmail: "sal@corp.com"},
+		{Name: "noemail", Author: "Al", AuthorEmail: ""},
+	}
+	var tests = []struct {
+		mode   string
+		values []string
+		want   []string
+	}{
+		{"", []string{"al"}, []string{"alice", "alan", "sal", "noemail"}},
+		{"exact", []string{"al"}, []string{"noemail"}},
+		{"exact", []string{"ALICE"}, []string{"alice"}},
+		{"exact", []string{"alan@example.com"}, []string{"alan"}},
+		{"substring", []string{"corp.com"}, []string{"sal"}},
+		{"substring", []string{"alice", "sal"}, []string{"alice", "sal"}},
+		{"regex", []string{"^al"}, []string{"alice", "alan", "noemail"}},
+		{"regex", []string{"^sal$", "@example"}, []string{"alice", "alan", "sal"}},
+		{"exact", []string{"me"}, []string{"alan"}},
+		{"regex", []string{"me", "^sal$"}, []string{"alan", "sal"}},
+	}
+	for _, test := range tests {
+		testname := fmt.Sprintf("%v %v", test.mode, test.values)
+		t.Run(testname, func(t *testing.T) {
+			filter := NewAuthorFilter(test.mode, test.values...).(*authorFilter)
+			filter.identity = func() (GitIdentity, error) {
+				return GitIdentity{Name: "", Email: "Alan@Example.com"}, nil
+			}
+			err := filter.Validate()
+			var got []string
+			for _, repo := range repos {
+				if filter.Match(repo) {
+					got = append(got, repo.Name)
+				}
+			}
+			if !reflect.DeepEqual(got, test.want) || err != nil {
+				t.Errorf(
+					"got (%v, %v)\nwant (%v, %v)",
+					got, err,
+					test.want, nil,
+				)
+			}
+		})
+	}
+}
+
+func TestAuthorFilterError(t *testing.T) {
+	var tests = []struct {
+		mode   string
+		values []string
+		wantE  error
+	}{
+		{"fuzzy", []string{"al"}, fmt.Errorf("fuzzy is not a valid author match option. Options: substring | exact | regex")},
+		{"regex", []string{"(al"}, fmt.Errorf("invalid author regex (al: error parsing regexp: missing closing ): `(?i)(al`")},
+		{"", []string{"me"}, fmt.Errorf("unable to find the author for 'me': user.name and user.email are not set in git config")},
+	}
+	for _, test := range tests {
+		testname := fmt.Sprintf("%v %v", test.mode, test.values)
+		t.Run(testname, func(t *testing.T) {
+			filter := NewAuthorFilter(test.mode, test.values...).(*authorFilter)
+			filter.identity = func() (GitIdentity, error) {
+				return GitIdentity{}, fmt.Errorf("user.name and user.email are not set in git config")
+			}
+			gotE := filter.Validate()
+			if gotE == nil || gotE.Error() != test.wantE.Error() {
+				t.Errorf(
+					"got (%v)\nwant (%v)",
+					gotE, test.wantE,
+				)
+			}
+		})
+	}
+}
+
+func TestRegistryValidate(t *testing.T) {
+	emptyQueries := NewRegistry()
+	validQueries := NewRegistry()
+	validQueries.Add(
+		NewSyncedFilter("y"),
+		NewLastModifiedFilter(">=2024-01-01"),
+		NewSorter("name"),
+	)
+	customQueries := NewRegistry()
+	customQueries.Add(
+		FilterFunc(func(repo Repo) bool { return repo.SyncedWithRemote }),
+		SortFunc(func(a, b Repo) int { return 0 }),
+	)
 
-	var tests = []*queries{
+	var tests = []*Registry{
 		emptyQueries,
 		validQueries,
-		emptyAndValidQueries,
+		customQueries,
 	}
 
 	for _, test := range tests {
-		testname := fmt.Sprintf("%v", test)
+		testname := fmt.Sprintf("%v", test.Queries())
 		t.Run(testname, func(t *testing.T) {
-			err := ValidateQueries(test)
+			err := test.Validate()
 			if err != nil {
 				t.Errorf("got (%v)\nwant (%v)", err, nil)
 			}
@@ -273,29 +437,36 @@ func TestValidateQueries(t *testing.T) {
 	}
 }
 
-func TestValidateQueriesError(t *testing.T) {
-	invalidQueries := NewQueries()
-	invalidQueries.LastModified.Value = ">=2024-23-01"
+func TestRegistryValidateError(t *testing.T) {
+	invalidQueries := NewRegistry()
+	invalidQueries.Add(
+		NewSyncedFilter("y"),
+		NewLastModifiedFilter(">=2024-23-01"),
+		NewSorter("invalid"),
+	)
 
+	// the error for the first invalid query is returned
 	wantE := fmt.Errorf("unexpected date 2024-23-01, date must be in the format yyyy-mm-dd and can only be prefixed with '<=', '>=', '<' or '>'")
-	gotE := ValidateQueries(invalidQueries)
+	gotE := invalidQueries.Validate()
 	if gotE == nil || gotE.Error() != wantE.Error() {
 		t.Errorf("got (%v)\nwant (%v)", gotE, wantE)
 	}
 }
 
-func TestAppplyQueries(t *testing.T) {
-	queries := NewQueries()
-	queries.Synced.Value = "y"
-	queries.LastModified.Value = ">=2024-01-02"
-	queries.Sort.Value = "name"
+func TestRegistryApply(t *testing.T) {
+	queries := NewRegistry()
+	queries.Add(
+		NewSyncedFilter("y"),
+		NewLastModifiedFilter(">=2024-01-02"),
+		NewSorter("name"),
+	)
 
 	repos := getInputRepos()
 	want := getApplyQueriesResult()
 
-	err := ApplyQueries(queries, &repos)
+	err := queries.Apply(&repos)
 
-	// ApplyQueries mutates the repos passed in
+	// Apply mutates the repos passed in
 	if !reflect.DeepEqual(repos, want) || err != nil {
 		t.Errorf(
 			"got (%v, %v)\nwant (%v, %v)",
@@ -305,53 +476,101 @@ func TestAppplyQueries(t *testing.T) {
 	}
 }
 
+func TestRegistryApplyCustomQueries(t *testing.T) {
+	// queries are applied in the order they are added so the reverse
+	// reverses the custom sort by author
+	queries := NewRegistry()
+	queries.Add(
+		FilterFunc(func(repo Repo) bool { return repo.Name != "d" }),
+		SortFunc(func(a, b Repo) int { return strings.Compare(a.Author, b.Author) }),
+		NewReverser(),
+	)
+
+	repos := getInputRepos()
+	want := []string{"e", "c", "a", "b"}
+
+	err := queries.Apply(&repos)
+	got := []string{}
+	for _, repo := range repos {
+		got = append(got, repo.Name)
+	}
+	if !reflect.DeepEqual(got, want) || err != nil {
+		t.Errorf(
+			"got (%v, %v)\nwant (%v, %v)",
+			got, err,
+			want, nil,
+		)
+	}
+}
+
+func TestRegistryMatch(t *testing.T) {
+	queries := NewRegistry()
+	queries.Add(
+		NewSyncedFilter("n"),
+		NewAuthorFilter("", "author ab"),
+		// sorts are ignored when matching a single repo
+		NewSorter("name"),
+	)
+
+	var got []string
+	for _, repo := range getInputRepos() {
+		if queries.Match(repo) {
+			got = append(got, repo.Name)
+		}
+	}
+	want := []string{"a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got (%v)\nwant (%v)", got, want)
+	}
+}
+
 func getInputRepos() []Repo {
 	// helper to provide fake input to test the sortFunc
 	return []Repo{
 		{
-			"e",
-			"repos/e",
-			"/home/user/repos/x/e",
-			jan3a,
-			true,
-			nil,
-			"author e",
+			Name:             "e",
+			Path:             "repos/e",
+			AbsPath:          "/home/user/repos/x/e",
+			LastModified:     jan3a,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author e",
 		},
 		{
-			"b",
-			"repos/b",
-			"/home/user/repos/y/b",
-			jan4,
-			true,
-			nil,
-			"author ab",
+			Name:             "b",
+			Path:             "repos/b",
+			AbsPath:          "/home/user/repos/y/b",
+			LastModified:     jan4,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"c",
-			"repos/c",
-			"/home/user/repos/z/c",
-			jan1,
-			false,
-			nil,
-			"author cd",
+			Name:             "c",
+			Path:             "repos/c",
+			AbsPath:          "/home/user/repos/z/c",
+			LastModified:     jan1,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"d",
-			"repos/d",
-			"/home/user/repos/w/d",
-			jan2,
-			true,
-			nil,
-			"author cd",
+			Name:             "d",
+			Path:             "repos/d",
+			AbsPath:          "/home/user/repos/w/d",
+			LastModified:     jan2,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"a",
-			"repos/a",
-			"/home/user/repos/x/a",
-			jan3,
-			false,
-			nil,
-			"author ab",
+			Name:             "a",
+			Path:             "repos/a",
+			AbsPath:          "/home/user/repos/x/a",
+			LastModified:     jan3,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 	}
 }
@@ -360,239 +579,239 @@ func getSortedOutput(key string) []Repo {
 	// helper to provide expected outputs for each sortFunc
 	sortedByName := []Repo{
 		{
-			"a",
-			"repos/a",
-			"/home/user/repos/x/a",
-			jan3,
-			false,
-			nil,
-			"author ab",
+			Name:             "a",
+			Path:             "repos/a",
+			AbsPath:          "/home/user/repos/x/a",
+			LastModified:     jan3,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"b",
-			"repos/b",
-			"/home/user/repos/y/b",
-			jan4,
-			true,
-			nil,
-			"author ab",
+			Name:             "b",
+			Path:             "repos/b",
+			AbsPath:          "/home/user/repos/y/b",
+			LastModified:     jan4,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"c",
-			"repos/c",
-			"/home/user/repos/z/c",
-			jan1,
-			false,
-			nil,
-			"author cd",
+			Name:             "c",
+			Path:             "repos/c",
+			AbsPath:          "/home/user/repos/z/c",
+			LastModified:     jan1,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"d",
-			"repos/d",
-			"/home/user/repos/w/d",
-			jan2,
-			true,
-			nil,
-			"author cd",
+			Name:             "d",
+			Path:             "repos/d",
+			AbsPath:          "/home/user/repos/w/d",
+			LastModified:     jan2,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"e",
-			"repos/e",
-			"/home/user/repos/x/e",
-			jan3a,
-			true,
-			nil,
-			"author e",
+			Name:             "e",
+			Path:             "repos/e",
+			AbsPath:          "/home/user/repos/x/e",
+			LastModified:     jan3a,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author e",
 		},
 	}
 	sortedByAbsPath := []Repo{
 		{
-			"d",
-			"repos/d",
-			"/home/user/repos/w/d",
-			jan2,
-			true,
-			nil,
-			"author cd",
+			Name:             "d",
+			Path:             "repos/d",
+			AbsPath:          "/home/user/repos/w/d",
+			LastModified:     jan2,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"a",
-			"repos/a",
-			"/home/user/repos/x/a",
-			jan3,
-			false,
-			nil,
-			"author ab",
+			Name:             "a",
+			Path:             "repos/a",
+			AbsPath:          "/home/user/repos/x/a",
+			LastModified:     jan3,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"e",
-			"repos/e",
-			"/home/user/repos/x/e",
-			jan3a,
-			true,
-			nil,
-			"author e",
+			Name:             "e",
+			Path:             "repos/e",
+			AbsPath:          "/home/user/repos/x/e",
+			LastModified:     jan3a,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author e",
 		},
 		{
-			"b",
-			"repos/b",
-			"/home/user/repos/y/b",
-			jan4,
-			true,
-			nil,
-			"author ab",
+			Name:             "b",
+			Path:             "repos/b",
+			AbsPath:          "/home/user/repos/y/b",
+			LastModified:     jan4,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"c",
-			"repos/c",
-			"/home/user/repos/z/c",
-			jan1,
-			false,
-			nil,
-			"author cd",
+			Name:             "c",
+			Path:             "repos/c",
+			AbsPath:          "/home/user/repos/z/c",
+			LastModified:     jan1,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 	}
 	sortedByLastModified := []Repo{
 		{
-			"c",
-			"repos/c",
-			"/home/user/repos/z/c",
-			jan1,
-			false,
-			nil,
-			"author cd",
+			Name:             "c",
+			Path:             "repos/c",
+			AbsPath:          "/home/user/repos/z/c",
+			LastModified:     jan1,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"d",
-			"repos/d",
-			"/home/user/repos/w/d",
-			jan2,
-			true,
-			nil,
-			"author cd",
+			Name:             "d",
+			Path:             "repos/d",
+			AbsPath:          "/home/user/repos/w/d",
+			LastModified:     jan2,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"a",
-			"repos/a",
-			"/home/user/repos/x/a",
-			jan3,
-			false,
-			nil,
-			"author ab",
+			Name:             "a",
+			Path:             "repos/a",
+			AbsPath:          "/home/user/repos/x/a",
+			LastModified:     jan3,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"e",
-			"repos/e",
-			"/home/user/repos/x/e",
-			jan3a,
-			true,
-			nil,
-			"author e",
+			Name:             "e",
+			Path:             "repos/e",
+			AbsPath:          "/home/user/repos/x/e",
+			LastModified:     jan3a,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author e",
 		},
 		{
-			"b",
-			"repos/b",
-			"/home/user/repos/y/b",
-			jan4,
-			true,
-			nil,
-			"author ab",
+			Name:             "b",
+			Path:             "repos/b",
+			AbsPath:          "/home/user/repos/y/b",
+			LastModified:     jan4,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 	}
 	sortedBySynced := []Repo{
 		{
-			"c",
-			"repos/c",
-			"/home/user/repos/z/c",
-			jan1,
-			false,
-			nil,
-			"author cd",
+			Name:             "c",
+			Path:             "repos/c",
+			AbsPath:          "/home/user/repos/z/c",
+			LastModified:     jan1,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"a",
-			"repos/a",
-			"/home/user/repos/x/a",
-			jan3,
-			false,
-			nil,
-			"author ab",
+			Name:             "a",
+			Path:             "repos/a",
+			AbsPath:          "/home/user/repos/x/a",
+			LastModified:     jan3,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"e",
-			"repos/e",
-			"/home/user/repos/x/e",
-			jan3a,
-			true,
-			nil,
-			"author e",
+			Name:             "e",
+			Path:             "repos/e",
+			AbsPath:          "/home/user/repos/x/e",
+			LastModified:     jan3a,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author e",
 		},
 		{
-			"b",
-			"repos/b",
-			"/home/user/repos/y/b",
-			jan4,
-			true,
-			nil,
-			"author ab",
+			Name:             "b",
+			Path:             "repos/b",
+			AbsPath:          "/home/user/repos/y/b",
+			LastModified:     jan4,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"d",
-			"repos/d",
-			"/home/user/repos/w/d",
-			jan2,
-			true,
-			nil,
-			"author cd",
+			Name:             "d",
+			Path:             "repos/d",
+			AbsPath:          "/home/user/repos/w/d",
+			LastModified:     jan2,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 	}
 	// since both b and a are from same author ab, input b and a will
 	// remain in their original positions according to the input repos
 	sortedByAuthor := []Repo{
 		{
-			"b",
-			"repos/b",
-			"/home/user/repos/y/b",
-			jan4,
-			true,
-			nil,
-			"author ab",
+			Name:             "b",
+			Path:             "repos/b",
+			AbsPath:          "/home/user/repos/y/b",
+			LastModified:     jan4,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"a",
-			"repos/a",
-			"/home/user/repos/x/a",
-			jan3,
-			false,
-			nil,
-			"author ab",
+			Name:             "a",
+			Path:             "repos/a",
+			AbsPath:          "/home/user/repos/x/a",
+			LastModified:     jan3,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"c",
-			"repos/c",
-			"/home/user/repos/z/c",
-			jan1,
-			false,
-			nil,
-			"author cd",
+			Name:             "c",
+			Path:             "repos/c",
+			AbsPath:          "/home/user/repos/z/c",
+			LastModified:     jan1,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"d",
-			"repos/d",
-			"/home/user/repos/w/d",
-			jan2,
-			true,
-			nil,
-			"author cd",
+			Name:             "d",
+			Path:             "repos/d",
+			AbsPath:          "/home/user/repos/w/d",
+			LastModified:     jan2,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"e",
-			"repos/e",
-			"/home/user/repos/x/e",
-			jan3a,
-			true,
-			nil,
-			"author e",
+			Name:             "e",
+			Path:             "repos/e",
+			AbsPath:          "/home/user/repos/x/e",
+			LastModified:     jan3a,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author e",
 		},
 	}
 	outputOptions := map[string][]Repo{
@@ -608,49 +827,49 @@ func getSortedOutput(key string) []Repo {
 func getReverseSortedByLastModifiedOutput() []Repo {
 	return []Repo{
 		{
-			"b",
-			"repos/b",
-			"/home/user/repos/y/b",
-			jan4,
-			true,
-			nil,
-			"author ab",
+			Name:             "b",
+			Path:             "repos/b",
+			AbsPath:          "/home/user/repos/y/b",
+			LastModified:     jan4,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"e",
-			"repos/e",
-			"/home/user/repos/x/e",
-			jan3a,
-			true,
-			nil,
-			"author e",
+			Name:             "e",
+			Path:             "repos/e",
+			AbsPath:          "/home/user/repos/x/e",
+			LastModified:     jan3a,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author e",
 		},
 		{
-			"a",
-			"repos/a",
-			"/home/user/repos/x/a",
-			jan3,
-			false,
-			nil,
-			"author ab",
+			Name:             "a",
+			Path:             "repos/a",
+			AbsPath:          "/home/user/repos/x/a",
+			LastModified:     jan3,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"d",
-			"repos/d",
-			"/home/user/repos/w/d",
-			jan2,
-			true,
-			nil,
-			"author cd",
+			Name:             "d",
+			Path:             "repos/d",
+			AbsPath:          "/home/user/repos/w/d",
+			LastModified:     jan2,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"c",
-			"repos/c",
-			"/home/user/repos/z/c",
-			jan1,
-			false,
-			nil,
-			"author cd",
+			Name:             "c",
+			Path:             "repos/c",
+			AbsPath:          "/home/user/repos/z/c",
+			LastModified:     jan1,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 	}
 }
@@ -659,51 +878,51 @@ func getFilteredOutputSynced(key string) []Repo {
 	// helper to provide expected outputs for each filter strategy apply
 	filteredBySyncYes := []Repo{
 		{
-			"e",
-			"repos/e",
-			"/home/user/repos/x/e",
-			jan3a,
-			true,
-			nil,
-			"author e",
+			Name:             "e",
+			Path:             "repos/e",
+			AbsPath:          "/home/user/repos/x/e",
+			LastModified:     jan3a,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author e",
 		},
 		{
-			"b",
-			"repos/b",
-			"/home/user/repos/y/b",
-			jan4,
-			true,
-			nil,
-			"author ab",
+			Name:             "b",
+			Path:             "repos/b",
+			AbsPath:          "/home/user/repos/y/b",
+			LastModified:     jan4,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"d",
-			"repos/d",
-			"/home/user/repos/w/d",
-			jan2,
-			true,
-			nil,
-			"author cd",
+			Name:             "d",
+			Path:             "repos/d",
+			AbsPath:          "/home/user/repos/w/d",
+			LastModified:     jan2,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 	}
 	filteredBySyncNo := []Repo{
 		{
-			"c",
-			"repos/c",
-			"/home/user/repos/z/c",
-			jan1,
-			false,
-			nil,
-			"author cd",
+			Name:             "c",
+			Path:             "repos/c",
+			AbsPath:          "/home/user/repos/z/c",
+			LastModified:     jan1,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"a",
-			"repos/a",
-			"/home/user/repos/x/a",
-			jan3,
-			false,
-			nil,
-			"author ab",
+			Name:             "a",
+			Path:             "repos/a",
+			AbsPath:          "/home/user/repos/x/a",
+			LastModified:     jan3,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 	}
 	outputOptions := map[string][]Repo{
@@ -716,120 +935,120 @@ func getFilteredOutputSynced(key string) []Repo {
 func getFilteredOutputLastModified(key string) []Repo {
 	filteredByLastModifiedEQjan3 := []Repo{
 		{
-			"e",
-			"repos/e",
-			"/home/user/repos/x/e",
-			jan3a,
-			true,
-			nil,
-			"author e",
+			Name:             "e",
+			Path:             "repos/e",
+			AbsPath:          "/home/user/repos/x/e",
+			LastModified:     jan3a,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author e",
 		},
 		{
-			"a",
-			"repos/a",
-			"/home/user/repos/x/a",
-			jan3,
-			false,
-			nil,
-			"author ab",
+			Name:             "a",
+			Path:             "repos/a",
+			AbsPath:          "/home/user/repos/x/a",
+			LastModified:     jan3,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 	}
 	filteredByLastModifiedLEQjan3 := []Repo{
 		{
-			"e",
-			"repos/e",
-			"/home/user/repos/x/e",
-			jan3a,
-			true,
-			nil,
-			"author e",
+			Name:             "e",
+			Path:             "repos/e",
+			AbsPath:          "/home/user/repos/x/e",
+			LastModified:     jan3a,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author e",
 		},
 		{
-			"c",
-			"repos/c",
-			"/home/user/repos/z/c",
-			jan1,
-			false,
-			nil,
-			"author cd",
+			Name:             "c",
+			Path:             "repos/c",
+			AbsPath:          "/home/user/repos/z/c",
+			LastModified:     jan1,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"d",
-			"repos/d",
-			"/home/user/repos/w/d",
-			jan2,
-			true,
-			nil,
-			"author cd",
+			Name:             "d",
+			Path:             "repos/d",
+			AbsPath:          "/home/user/repos/w/d",
+			LastModified:     jan2,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"a",
-			"repos/a",
-			"/home/user/repos/x/a",
-			jan3,
-			false,
-			nil,
-			"author ab",
+			Name:             "a",
+			Path:             "repos/a",
+			AbsPath:          "/home/user/repos/x/a",
+			LastModified:     jan3,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 	}
 	filteredByLastModifiedGEQjan3 := []Repo{
 		{
-			"e",
-			"repos/e",
-			"/home/user/repos/x/e",
-			jan3a,
-			true,
-			nil,
-			"author e",
+			Name:             "e",
+			Path:             "repos/e",
+			AbsPath:          "/home/user/repos/x/e",
+			LastModified:     jan3a,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author e",
 		},
 		{
-			"b",
-			"repos/b",
-			"/home/user/repos/y/b",
-			jan4,
-			true,
-			nil,
-			"author ab",
+			Name:             "b",
+			Path:             "repos/b",
+			AbsPath:          "/home/user/repos/y/b",
+			LastModified:     jan4,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"a",
-			"repos/a",
-			"/home/user/repos/x/a",
-			jan3,
-			false,
-			nil,
-			"author ab",
+			Name:             "a",
+			Path:             "repos/a",
+			AbsPath:          "/home/user/repos/x/a",
+			LastModified:     jan3,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 	}
 	filteredByLastModifiedLESjan3 := []Repo{
 		{
-			"c",
-			"repos/c",
-			"/home/user/repos/z/c",
-			jan1,
-			false,
-			nil,
-			"author cd",
+			Name:             "c",
+			Path:             "repos/c",
+			AbsPath:          "/home/user/repos/z/c",
+			LastModified:     jan1,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"d",
-			"repos/d",
-			"/home/user/repos/w/d",
-			jan2,
-			true,
-			nil,
-			"author cd",
+			Name:             "d",
+			Path:             "repos/d",
+			AbsPath:          "/home/user/repos/w/d",
+			LastModified:     jan2,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 	}
 	filteredByLastModifiedGRTjan3 := []Repo{
 		{
-			"b",
-			"repos/b",
-			"/home/user/repos/y/b",
-			jan4,
-			true,
-			nil,
-			"author ab",
+			Name:             "b",
+			Path:             "repos/b",
+			AbsPath:          "/home/user/repos/y/b",
+			LastModified:     jan4,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 	}
 	outputOptions := map[string][]Repo{
@@ -845,53 +1064,53 @@ func getFilteredOutputLastModified(key string) []Repo {
 func getFilteredOutputAuthor(key string) []Repo {
 	filteredByAuthorAB := []Repo{
 		{
-			"b",
-			"repos/b",
-			"/home/user/repos/y/b",
-			jan4,
-			true,
-			nil,
-			"author ab",
+			Name:             "b",
+			Path:             "repos/b",
+			AbsPath:          "/home/user/repos/y/b",
+			LastModified:     jan4,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 		{
-			"a",
-			"repos/a",
-			"/home/user/repos/x/a",
-			jan3,
-			false,
-			nil,
-			"author ab",
+			Name:             "a",
+			Path:             "repos/a",
+			AbsPath:          "/home/user/repos/x/a",
+			LastModified:     jan3,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author ab",
 		},
 	}
 	filteredByAuthorCD := []Repo{
 		{
-			"c",
-			"repos/c",
-			"/home/user/repos/z/c",
-			jan1,
-			false,
-			nil,
-			"author cd",
+			Name:             "c",
+			Path:             "repos/c",
+			AbsPath:          "/home/user/repos/z/c",
+			LastModified:     jan1,
+			SyncedWithRemote: false,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 		{
-			"d",
-			"repos/d",
-			"/home/user/repos/w/d",
-			jan2,
-			true,
-			nil,
-			"author cd",
+			Name:             "d",
+			Path:             "repos/d",
+			AbsPath:          "/home/user/repos/w/d",
+			LastModified:     jan2,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author cd",
 		},
 	}
 	filteredByAuthorE := []Repo{
 		{
-			"e",
-			"repos/e",
-			"/home/user/repos/x/e",
-			jan3a,
-			true,
-			nil,
-			"author e",
+			Name:             "e",
+			Path:             "repos/e",
+			AbsPath:          "/home/user/repos/x/e",
+			LastModified:     jan3a,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author e",
 		},
 	}
 	var filteredByAuthorZ []Repo
@@ -910,31 +1129,31 @@ func getApplyQueriesResult() []Repo {
 	// Sorted by Name
 	return []Repo{
 		{
-			"b",
-			"repos/b",
-			"/home/user/repos/y/b",
-			jan4,
-			true,
-			nil,
-			"author ab",
-		},
-		{
-			"d",
-			"repos/d",
-			"/home/user/repos/w/d",
-			jan2,
-			true,
-			nil,
-			"author cd",
-		},
-		{
-			"e",
-			"repos/e",
-			"/home/user/repos/x/e",
-			jan3a,
-			true,
-			nil,
-			"author e",
+			Name:             "b",
+			Path:             "repos/b",
+			AbsPath:          "/home/user/repos/y/b",
+			LastModified:     jan4,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author ab",
+		},
+		{
+			Name:             "d",
+			Path:             "repos/d",
+			AbsPath:          "/home/user/repos/w/d",
+			LastModified:     jan2,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author cd",
+		},
+		{
+			Name:             "e",
+			Path:             "repos/e",
+			AbsPath:          "/home/user/repos/x/e",
+			LastModified:     jan3a,
+			SyncedWithRemote: true,
+			SyncDetails:      nil,
+			Author:           "author e",
 		},
 	}
 }
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"strconv"
+	"strings"
+)
+
+// how many of the last commits are in RepoInfo
+const recentCommitCount = 10
+
+// details of a repo that are only gathered when they are looked at
+type RepoInfo struct {
+	// each local branch with its upstream branch and how far apart they are
+	// such as "* main -> origin/main [ahead 1]"
+	Branches []string `json:"branches"`
+	// output of git status -s for each file with uncommitted changes
+	StatusFiles []string `json:"statusFiles"`
+	// the most recent commits on the current branch such as
+	// "1a2b3c4 fix typo (Foo Bar, 2 days ago)"
+	RecentCommits []string `json:"recentCommits"`
+}
+
+func GetRepoInfo(repo Repo) (RepoInfo, error) {
+	var info RepoInfo
+	branches, err := gitOutput(repo.AbsPath, "for-each-ref",
+		"--format=%(if)%(HEAD)%(then)*%(else) %(end) %(refname:short)%(if)%(upstream)%(then) -> %(upstream:short)%(end)%(if)%(upstream:track)%(then) %(upstream:track)%(end)",
+		"refs/heads")
+	if err != nil {
+		return info, err
+	}
+	info.Branches = outputLines(branches)
+	status, err := gitOutput(repo.AbsPath, "status", "-s")
+	if err != nil {
+		return info, err
+	}
+	info.StatusFiles = outputLines(status)
+	// fails when there are no commits yet
+	commits, err := gitOutput(repo.AbsPath, "log", "-n", strconv.Itoa(recentCommitCount), "--format=%h %s (%an, %ar)")
+	if err == nil {
+		info.RecentCommits = outputLines(commits)
+	}
+	return info, nil
+}
+
+// splits the output of a git command into lines without the trailing newline
+func outputLines(out string) []string {
+	out = strings.TrimRight(out, "\n")
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"strings"
+)
+
+//go:embed templates/report.html
+var reportTemplate string
+
+var markdownEscaper = strings.NewReplacer(
+	"|", "\\|",
+	"\r\n", "<br>",
+	"\n", "<br>",
+)
+
+// returns a github flavoured markdown table followed by the summary.
+// columnNames selects the columns in the given order. The default columns
+// are used when columnNames is nil. Dates are shown in the date format dates
+func ConstructMarkdownOutput(repos []Repo, columnNames []string, summary string, dates DateFormat) string {
+	columns := selectColumns(columnNames)
+	var headers, separators []string
+	for _, c := range columns {
+		headers = append(headers, c.tableHeader)
+		separators = append(separators, "---")
+	}
+	output := "| " + strings.Join(headers, " | ") + " |\n"
+	output += "| " + strings.Join(separators, " | ") + " |\n"
+	for _, repo := range repos {
+		var values []string
+		for _, c := range columns {
+			var value string
+			if c.list != nil {
+				// markdown tables cannot contain lists so each item is
+				// shown on its own line within the cell
+				var items []string
+				for _, item := range c.list(repo) {
+					items = append(items, "- "+markdownEscaper.Replace(item))
+				}
+				value = strings.Join(items, "<br>")
+			} else {
+				value = markdownEscaper.Replace(c.textValue(repo, dates))
+			}
+			values = append(values, value)
+		}
+		output += "| " + strings.Join(values, " | ") + " |\n"
+	}
+	return output + "\n" + summary + "\n"
+}
+
+type reportCell struct {
+	Text string
+	List []string
+	// value used to sort the column in the report
+	Sort string
+}
+
+type reportRow struct {
+	Synced bool
+	Cells  []reportCell
+}
+
+type reportData struct {
+	Headers []string
+	Rows    []reportRow
+	Summary string
+}
+
+// returns a self-contained html report with a table that can be sorted by
+// clicking on the headers followed by the summary. columnNames selects the
+// columns in the given order. The default columns are used when columnNames
+// is nil. Dates are shown in the date format dates
+func ConstructHTMLOutput(repos []Repo, columnNames []string, summary string, dates DateFormat) (string, error) {
+	t, err := template.New("report").Parse(reportTemplate)
+	if err != nil {
+		return "", err
+	}
+	columns := selectColumns(columnNames)
+	data := reportData{Summary: summary}
+	for _, c := range columns {
+		data.Headers = append(data.Headers, c.tableHeader)
+	}
+	for _, repo := range repos {
+		row := reportRow{Synced: repo.SyncedWithRemote}
+		for _, c := range columns {
+			cell := reportCell{Text: c.tableValue(repo, dates), Sort: c.sortValue(repo, dates)}
+			if c.list != nil {
+				cell.List = c.list(repo)
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		data.Rows = append(data.Rows, row)
+	}
+	var buf bytes.Buffer
+	err = t.Execute(&buf, data)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
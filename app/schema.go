@@ -0,0 +1,122 @@
+package app
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// version of the json output. It is only incremented when a field is removed
+// or changes meaning. Adding fields does not change the version, so consumers
+// should ignore fields they do not know
+const SchemaVersion = 1
+
+// the json output. Repos are wrapped so that details about the run can be
+// added without breaking consumers of the repos
+type Output struct {
+	SchemaVersion int       `json:"schemaVersion" doc:"version of the output, incremented only for breaking changes"`
+	GeneratedAt   time.Time `json:"generatedAt" doc:"time the output was generated"`
+	Root          string    `json:"root" doc:"directory that was searched for repos"`
+	Summary       Summary   `json:"summary" doc:"counts for all the repos that matched the filters"`
+	Repos         []Repo    `json:"repos" doc:"repos in the output after sorting, limit and offset, empty when grouping since the repos are in their group"`
+	Groups        []Group   `json:"groups,omitempty" doc:"repos bucketed by --group-by, only present when grouping"`
+}
+
+// returns the output for repos. summary should be for all the repos that
+// matched the queries even if only some of them are in repos
+func NewOutput(repos []Repo, root string, summary Summary, generatedAt time.Time) Output {
+	// initialize as non-nil empty slice so that json output after marshalling
+	// will be [] instead of null
+	if repos == nil {
+		repos = []Repo{}
+	}
+	return Output{
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   generatedAt,
+		Root:          root,
+		Summary:       summary,
+		Repos:         repos,
+	}
+}
+
+// the fields of these types can be left out of the output with --columns so
+// they are not required by the schema
+var partialSchemaTypes = []reflect.Type{reflect.TypeOf(Repo{})}
+
+// returns the json schema of Output. The schema is generated from the go types
+// so that it cannot drift from the output
+func JSONSchema() string {
+	defs := map[string]any{}
+	schema := map[string]any{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"title":       "repocheck output",
+		"description": "output of repocheck --format json",
+	}
+	for key, value := range structSchema(reflect.TypeOf(Output{}), defs) {
+		schema[key] = value
+	}
+	schema["$defs"] = defs
+	jsonOutput, _ := json.MarshalIndent(schema, "", "\t")
+	return string(jsonOutput) + "\n"
+}
+
+// returns the schema for a value of type t. Structs other than time.Time are
+// added to defs and referenced so that each is only described once
+func typeSchema(t reflect.Type, defs map[string]any) map[string]any {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), defs)
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), defs)}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return map[string]any{"type": "string", "format": "date-time"}
+		}
+		if _, ok := defs[t.Name()]; !ok {
+			// reserve the name first in case the struct refers to itself
+			defs[t.Name()] = nil
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	default:
+		panic("no json schema for type " + t.String())
+	}
+}
+
+func structSchema(t reflect.Type, defs map[string]any) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := typeSchema(field.Type, defs)
+		if doc := field.Tag.Get("doc"); doc != "" {
+			property["description"] = doc
+		}
+		properties[name] = property
+		if options != "omitempty" && !slices.Contains(partialSchemaTypes, t) {
+			required = append(required, name)
+		}
+	}
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
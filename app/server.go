@@ -0,0 +1,306 @@
+package app
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed templates/dashboard.html
+var dashboardPage []byte
+
+// serves the repos found in a directory over http. The repos and their
+// details are found again in the background every interval and when a refresh
+// is requested, and are kept between requests so that requests never wait for
+// git
+type Server struct {
+	root  string
+	fetch bool
+	mu    sync.RWMutex
+	repos []Repo
+	// the branches, uncommitted changes and recent commits of the repos by
+	// Repo.Path. Repos whose details could not be read are left out
+	infos map[string]RepoInfo
+	// when the last scan finished, zero before the first scan
+	scannedAt time.Time
+	scanErr   error
+	scanning  bool
+	// a scan that is requested while a scan is running is run after it
+	refresh chan struct{}
+}
+
+// returns a server for the repos in root. The repos are fetched before each
+// scan when fetch is true. Run has to be called to start scanning
+func NewServer(root string, fetch bool) *Server {
+	return &Server{root: root, fetch: fetch, refresh: make(chan struct{}, 1)}
+}
+
+// scans straight away, then every interval and whenever a refresh is
+// requested until stop is closed
+func (s *Server) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.Scan()
+		select {
+		case <-ticker.C:
+		case <-s.refresh:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// finds the repos and their details and replaces the cached repos with them
+func (s *Server) Scan() {
+	s.mu.Lock()
+	s.scanning = true
+	s.mu.Unlock()
+	repos, err := GetReposWithDetails(s.root, s.fetch)
+	var infos map[string]RepoInfo
+	if err == nil {
+		infos = getRepoInfos(repos)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scanning = false
+	s.scanErr = err
+	if err != nil {
+		slog.Warn(fmt.Sprintf("Unable to find repos in %v, %v", s.root, err))
+		return
+	}
+	s.repos = repos
+	s.infos = infos
+	s.scannedAt = time.Now().UTC().Truncate(time.Second)
+}
+
+// returns the details of repos by Repo.Path. Repos whose details cannot be
+// read are left out
+func getRepoInfos(repos []Repo) map[string]RepoInfo {
+	infos := make([]RepoInfo, len(repos))
+	errs := make([]error, len(repos))
+	ForEachRepo(repos, 8, func(i int, repo Repo) {
+		infos[i], errs[i] = GetRepoInfo(repo)
+	})
+	byPath := map[string]RepoInfo{}
+	for i, repo := range repos {
+		if errs[i] != nil {
+			slog.Warn(fmt.Sprintf("Unable to read the details of %v, %v", repo.AbsPath, errs[i]))
+			continue
+		}
+		byPath[repo.Path] = infos[i]
+	}
+	return byPath
+}
+
+// requests a scan without waiting for it
+func (s *Server) requestScan() {
+	select {
+	case s.refresh <- struct{}{}:
+	default:
+		// a scan is already waiting to run
+	}
+}
+
+// returns the handler for the dashboard and the api
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(dashboardPage)
+	})
+	mux.HandleFunc("GET /api/repos", s.handleRepos)
+	mux.HandleFunc("GET /api/repos/{path...}", s.handleRepo)
+	mux.HandleFunc("POST /api/refresh", s.handleRefresh)
+	return mux
+}
+
+// returns the cached repos and when they were found. ok is false before the
+// first scan has finished
+func (s *Server) cached() (repos []Repo, scannedAt time.Time, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.repos), s.scannedAt, !s.scannedAt.IsZero()
+}
+
+// responds with the json output of repocheck for the repos that match the
+// query parameters, which are named after the flags of the same name
+func (s *Server) handleRepos(w http.ResponseWriter, r *http.Request) {
+	repos, scannedAt, ok := s.cached()
+	if !ok {
+		writeJSONError(w, http.StatusServiceUnavailable, errors.New("the first scan has not finished"))
+		return
+	}
+	params, err := parseRepoParams(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	err = params.queries.Apply(&repos)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	matchedRepos := repos
+	Paginate(&repos, params.offset, params.limit)
+	output := NewOutput(repos, s.root, Summarize(matchedRepos), scannedAt)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(ConstructJSONOutput(output, params.columns)))
+}
+
+// the query parameters of /api/repos
+type repoParams struct {
+	queries *Registry
+	offset  int
+	limit   int
+	columns []string
+}
+
+func parseRepoParams(r *http.Request) (repoParams, error) {
+	query := r.URL.Query()
+	params := repoParams{queries: NewRegistry()}
+	var loc *time.Location
+	if tz := query.Get("tz"); tz != "" {
+		var err error
+		loc, err = time.LoadLocation(tz)
+		if err != nil {
+			return params, fmt.Errorf("%v is not a valid time zone", tz)
+		}
+	}
+	if value := query.Get("lastmodified"); value != "" {
+		params.queries.Add(NewLastModifiedFilterIn(value, loc))
+	}
+	if value := query.Get("synced"); value != "" {
+		params.queries.Add(NewSyncedFilter(value))
+	}
+	if values := query["author"]; len(values) > 0 {
+		authorMatch := query.Get("author-match")
+		if authorMatch == "" {
+			authorMatch = "substring"
+		}
+		params.queries.Add(NewAuthorFilter(authorMatch, values...))
+	}
+	sortValue := query.Get("sort")
+	if sortValue == "" {
+		sortValue = "lastmodified"
+	}
+	params.queries.Add(NewSorter(sortValue))
+	if reverse, _ := strconv.ParseBool(query.Get("reverse")); reverse {
+		params.queries.Add(NewReverser())
+	}
+	err := params.queries.Validate()
+	if err != nil {
+		return params, err
+	}
+	for _, name := range []string{"offset", "limit"} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return params, fmt.Errorf("%v must be a number", name)
+		}
+		if name == "offset" {
+			params.offset = n
+		} else {
+			params.limit = n
+		}
+	}
+	err = ValidatePagination(params.offset, params.limit)
+	if err != nil {
+		return params, err
+	}
+	if value := query.Get("columns"); value != "" {
+		params.columns = strings.Split(value, ",")
+		err = ValidateColumns(params.columns)
+		if err != nil {
+			return params, err
+		}
+	}
+	return params, nil
+}
+
+// the response of /api/repos/{path}
+type repoDetail struct {
+	Repo Repo `json:"repo"`
+	RepoInfo
+}
+
+// responds with the cached repo at path, which is relative to the root like
+// Repo.Path, along with its branches, uncommitted changes and recent commits
+// as of the last scan
+func (s *Server) handleRepo(w http.ResponseWriter, r *http.Request) {
+	path := r.PathValue("path")
+	// the repo and its details are read together so that they are from the
+	// same scan
+	s.mu.RLock()
+	scanned := !s.scannedAt.IsZero()
+	i := slices.IndexFunc(s.repos, func(repo Repo) bool { return repo.Path == path })
+	var repo Repo
+	if i != -1 {
+		repo = s.repos[i]
+	}
+	info, hasInfo := s.infos[path]
+	s.mu.RUnlock()
+	switch {
+	case !scanned:
+		writeJSONError(w, http.StatusServiceUnavailable, errors.New("the first scan has not finished"))
+		return
+	case i == -1:
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("no repo at %v", path))
+		return
+	case !hasInfo:
+		writeJSONError(w, http.StatusInternalServerError, fmt.Errorf("the details of %v could not be read during the last scan", path))
+		return
+	}
+	// initialize as non-nil empty slices so that json output after
+	// marshalling will be [] instead of null
+	for _, list := range []*[]string{&info.Branches, &info.StatusFiles, &info.RecentCommits} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	if repo.SyncDetails == nil {
+		repo.SyncDetails = []string{}
+	}
+	writeJSON(w, http.StatusOK, repoDetail{repo, info})
+}
+
+// requests a scan and responds straight away since scans with fetch can take
+// minutes
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	s.requestScan()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	response := map[string]any{"status": "scan requested", "scanning": s.scanning}
+	if !s.scannedAt.IsZero() {
+		response["lastScan"] = s.scannedAt
+	}
+	if s.scanErr != nil {
+		response["lastError"] = s.scanErr.Error()
+	}
+	writeJSON(w, http.StatusAccepted, response)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	// branches are shown as "main -> origin/main"
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
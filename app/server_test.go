@@ -0,0 +1,142 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer() *Server {
+	s := NewServer("/home/user", false)
+	s.repos = []Repo{
+		{Name: "b", Path: "b", AbsPath: "/home/user/b", SyncedWithRemote: true, Author: "Foo"},
+		{Name: "a", Path: "work/a", AbsPath: "/home/user/work/a", SyncDetails: []string{"uncommitted changes"}, Author: "Bar"},
+		{Name: "c", Path: "c", AbsPath: "/home/user/c", SyncDetails: []string{"branch(es) ahead"}, Author: "Foo"},
+	}
+	// c has no details because they could not be read during the scan
+	s.infos = map[string]RepoInfo{
+		"b":      {Branches: []string{"* main -> origin/main"}},
+		"work/a": {Branches: []string{"* main"}, StatusFiles: []string{" M main.go"}},
+	}
+	s.scannedAt = time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	return s
+}
+
+func TestServerRepos(t *testing.T) {
+	var tests = []struct {
+		url       string
+		wantNames []string
+		wantTotal int
+	}{
+		{"/api/repos?sort=name", []string{"a", "b", "c"}, 3},
+		{"/api/repos?synced=n&sort=name&reverse=true", []string{"c", "a"}, 2},
+		{"/api/repos?author=foo&sort=name", []string{"b", "c"}, 2},
+		{"/api/repos?sort=name&offset=1&limit=1", []string{"b"}, 3},
+	}
+	handler := newTestServer().Handler()
+	for _, test := range tests {
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, httptest.NewRequest("GET", test.url, nil))
+		if response.Code != http.StatusOK {
+			t.Errorf("%v: got status %v, want 200", test.url, response.Code)
+			continue
+		}
+		var output Output
+		err := json.Unmarshal(response.Body.Bytes(), &output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, repo := range output.Repos {
+			names = append(names, repo.Name)
+		}
+		if diff := cmp.Diff(test.wantNames, names); diff != "" {
+			t.Errorf("%v: -want +got:\n%s", test.url, diff)
+		}
+		if output.Summary.Total != test.wantTotal {
+			t.Errorf("%v: got total %v, want %v", test.url, output.Summary.Total, test.wantTotal)
+		}
+	}
+}
+
+func TestServerRepo(t *testing.T) {
+	// the repos do not exist on disk so the details can only come from the
+	// scan
+	handler := newTestServer().Handler()
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest("GET", "/api/repos/work/a", nil))
+	if response.Code != http.StatusOK {
+		t.Fatalf("got status %v %v, want 200", response.Code, response.Body.String())
+	}
+	var got repoDetail
+	err := json.Unmarshal(response.Body.Bytes(), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := repoDetail{
+		Repo:     Repo{Name: "a", Path: "work/a", AbsPath: "/home/user/work/a", SyncDetails: []string{"uncommitted changes"}, Author: "Bar"},
+		RepoInfo: RepoInfo{Branches: []string{"* main"}, StatusFiles: []string{" M main.go"}, RecentCommits: []string{}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	var tests = []struct {
+		method     string
+		url        string
+		wantStatus int
+		wantError  string
+	}{
+		{"GET", "/api/repos?sort=bogus", http.StatusBadRequest, "bogus is not a valid sort option"},
+		{"GET", "/api/repos?limit=x", http.StatusBadRequest, "limit must be a number"},
+		{"GET", "/api/repos?columns=name,bogus", http.StatusBadRequest, "bogus"},
+		{"GET", "/api/repos/nope", http.StatusNotFound, "no repo at nope"},
+		{"GET", "/api/repos/c", http.StatusInternalServerError, "the details of c could not be read"},
+		{"DELETE", "/api/repos", http.StatusMethodNotAllowed, ""},
+	}
+	handler := newTestServer().Handler()
+	for _, test := range tests {
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, httptest.NewRequest(test.method, test.url, nil))
+		if response.Code != test.wantStatus || !strings.Contains(response.Body.String(), test.wantError) {
+			t.Errorf("%v %v: got %v %v, want %v with %q", test.method, test.url, response.Code, response.Body.String(), test.wantStatus, test.wantError)
+		}
+	}
+}
+
+func TestServerBeforeFirstScan(t *testing.T) {
+	handler := NewServer("/home/user", false).Handler()
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest("GET", "/api/repos", nil))
+	if response.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %v, want 503", response.Code)
+	}
+}
+
+func TestServerRefresh(t *testing.T) {
+	s := newTestServer()
+	response := httptest.NewRecorder()
+	s.Handler().ServeHTTP(response, httptest.NewRequest("POST", "/api/refresh", nil))
+	if response.Code != http.StatusAccepted {
+		t.Errorf("got status %v, want 202", response.Code)
+	}
+	select {
+	case <-s.refresh:
+	default:
+		t.Errorf("no scan was requested")
+	}
+}
+
+func TestServerDashboard(t *testing.T) {
+	response := httptest.NewRecorder()
+	newTestServer().Handler().ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), "<title>Repocheck</title>") {
+		t.Errorf("got status %v, want the dashboard", response.Code)
+	}
+}
@@ -0,0 +1,296 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// layout of the names of snapshot files. Times are in UTC so that names sort
+// in the order the snapshots were taken and contain no characters that are
+// invalid in file names on any platform. Snapshots taken in the same second
+// as an earlier snapshot have a suffix such as -2
+const snapshotLayout = "2006-01-02T150405Z"
+
+// returns when the snapshot called name was taken and its number within that
+// second, which is 1 for the first snapshot. ok is false if name is not the
+// name of a snapshot
+func parseSnapshotName(name string) (taken time.Time, n int, ok bool) {
+	if len(name) < len(snapshotLayout) {
+		return time.Time{}, 0, false
+	}
+	taken, err := time.Parse(snapshotLayout, name[:len(snapshotLayout)])
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	suffix := name[len(snapshotLayout):]
+	if suffix == "" {
+		return taken, 1, true
+	}
+	n, err = strconv.Atoi(strings.TrimPrefix(suffix, "-"))
+	if !strings.HasPrefix(suffix, "-") || err != nil || n < 2 {
+		return time.Time{}, 0, false
+	}
+	return taken, n, true
+}
+
+// returns the directory that snapshots are saved in. The directory can be
+// overridden with the REPOCHECK_DATA_DIR environment variable
+func SnapshotDir() (string, error) {
+	if dir := os.Getenv("REPOCHECK_DATA_DIR"); dir != "" {
+		return filepath.Join(dir, "snapshots"), nil
+	}
+	var dataDir string
+	switch {
+	case os.Getenv("XDG_DATA_HOME") != "":
+		dataDir = os.Getenv("XDG_DATA_HOME")
+	case runtime.GOOS == "linux":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataDir = filepath.Join(home, ".local", "share")
+	default:
+		// the config directory is also where applications keep their data on
+		// macOS and windows
+		var err error
+		dataDir, err = os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dataDir, "repocheck", "snapshots"), nil
+}
+
+// saves output in dir in a file named after output.GeneratedAt and returns
+// the path of the file. Snapshots that already exist are never replaced
+func SaveSnapshot(dir string, output Output) (string, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+	base := output.GeneratedAt.UTC().Format(snapshotLayout)
+	for n := 1; ; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%v-%d", base, n)
+		}
+		path := filepath.Join(dir, name+".json")
+		// O_EXCL keeps a snapshot saved in the same second from being
+		// replaced, in which case the next number is tried
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.WriteString(ConstructJSONOutput(output, nil))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+}
+
+// returns the names of the snapshots in dir from oldest to newest. A missing
+// dir has no snapshots
+func ListSnapshots(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if _, _, isSnapshot := parseSnapshotName(name); ok && isSnapshot {
+			names = append(names, name)
+		}
+	}
+	// sorting the names as text would put -10 before -2
+	slices.SortFunc(names, func(a, b string) int {
+		takenA, nA, _ := parseSnapshotName(a)
+		takenB, nB, _ := parseSnapshotName(b)
+		if c := takenA.Compare(takenB); c != 0 {
+			return c
+		}
+		return nA - nB
+	})
+	return names, nil
+}
+
+// returns the path of the snapshot in dir for arg, which is either the name
+// of a snapshot, the path of a snapshot file or a date in the form yyyy-mm-dd.
+// A date selects the last snapshot taken by the end of that day in loc
+func ResolveSnapshot(dir string, arg string, loc *time.Location) (string, error) {
+	names, err := ListSnapshots(dir)
+	if err != nil {
+		return "", err
+	}
+	if slices.Contains(names, arg) {
+		return filepath.Join(dir, arg+".json"), nil
+	}
+	if _, err := os.Stat(arg); err == nil {
+		return arg, nil
+	}
+	day, err := time.ParseInLocation(time.DateOnly, arg, loc)
+	if err != nil {
+		return "", fmt.Errorf("%v is not a snapshot, a snapshot file or a date in the form yyyy-mm-dd", arg)
+	}
+	end := day.AddDate(0, 0, 1)
+	for i := len(names) - 1; i >= 0; i-- {
+		taken, _, _ := parseSnapshotName(names[i])
+		if taken.Before(end) {
+			return filepath.Join(dir, names[i]+".json"), nil
+		}
+	}
+	return "", fmt.Errorf("there is no snapshot from %v or earlier", arg)
+}
+
+func LoadSnapshot(path string) (Output, error) {
+	var output Output
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return output, err
+	}
+	err = json.Unmarshal(data, &output)
+	if err != nil {
+		return output, fmt.Errorf("invalid snapshot %v: %v", path, err)
+	}
+	return output, nil
+}
+
+// the changes between two snapshots. Repos are matched by their absolute path
+type SnapshotDiff struct {
+	From          time.Time    `json:"from"`
+	To            time.Time    `json:"to"`
+	Added         []Repo       `json:"added"`
+	Removed       []Repo       `json:"removed"`
+	NewlyUnsynced []Repo       `json:"newlyUnsynced"`
+	NewlySynced   []Repo       `json:"newlySynced"`
+	Branch        []RepoChange `json:"branchChanged"`
+	Activity      []RepoChange `json:"activity"`
+}
+
+// a repo that is in both snapshots with a value that changed
+type RepoChange struct {
+	Repo Repo   `json:"repo"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// returns the changes from the snapshot from to the snapshot to. Activity is
+// a change to the last modified time or the author of the last commit, with
+// the times shown in the date format dates
+func DiffSnapshots(from Output, to Output, dates DateFormat) SnapshotDiff {
+	diff := SnapshotDiff{
+		From: from.GeneratedAt, To: to.GeneratedAt,
+		// initialize as non-nil empty slices so that json output after
+		// marshalling will be [] instead of null
+		Added: []Repo{}, Removed: []Repo{}, NewlyUnsynced: []Repo{}, NewlySynced: []Repo{},
+		Branch: []RepoChange{}, Activity: []RepoChange{},
+	}
+	before := map[string]Repo{}
+	for _, repo := range from.Repos {
+		before[repo.AbsPath] = repo
+	}
+	after := map[string]bool{}
+	for _, repo := range to.Repos {
+		after[repo.AbsPath] = true
+		old, ok := before[repo.AbsPath]
+		if !ok {
+			diff.Added = append(diff.Added, repo)
+			continue
+		}
+		switch {
+		case old.SyncedWithRemote && !repo.SyncedWithRemote:
+			diff.NewlyUnsynced = append(diff.NewlyUnsynced, repo)
+		case !old.SyncedWithRemote && repo.SyncedWithRemote:
+			diff.NewlySynced = append(diff.NewlySynced, repo)
+		}
+		if old.Branch != repo.Branch {
+			diff.Branch = append(diff.Branch, RepoChange{repo, old.Branch, repo.Branch})
+		}
+		if !old.LastModified.Equal(repo.LastModified) || old.Author != repo.Author {
+			diff.Activity = append(diff.Activity, RepoChange{repo, activity(old, dates), activity(repo, dates)})
+		}
+	}
+	for _, repo := range from.Repos {
+		if !after[repo.AbsPath] {
+			diff.Removed = append(diff.Removed, repo)
+		}
+	}
+	return diff
+}
+
+// describes the last activity in repo such as "2024-01-02 15:04 by Foo Bar"
+func activity(repo Repo, dates DateFormat) string {
+	return dates.Format(repo.LastModified) + " by " + repo.Author
+}
+
+// returns the diff as text with a section for each kind of change that
+// happened. The times of the snapshots are shown in the date format dates
+func ConstructDiffOutput(diff SnapshotDiff, dates DateFormat) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Changes from %v to %v\n", dates.Format(diff.From), dates.Format(diff.To))
+	sections := []struct {
+		title string
+		lines []string
+	}{
+		{"Added", diffRepoLines(diff.Added, "+")},
+		{"Removed", diffRepoLines(diff.Removed, "-")},
+		{"Newly not synced", diffRepoLines(diff.NewlyUnsynced, "✗")},
+		{"Newly synced", diffRepoLines(diff.NewlySynced, "✓")},
+		{"Branch changed", diffChangeLines(diff.Branch)},
+		{"Activity", diffChangeLines(diff.Activity)},
+	}
+	changed := false
+	for _, section := range sections {
+		if len(section.lines) == 0 {
+			continue
+		}
+		changed = true
+		fmt.Fprintf(&buf, "\n%v (%v)\n", section.title, len(section.lines))
+		for _, line := range section.lines {
+			buf.WriteString("  " + line + "\n")
+		}
+	}
+	if !changed {
+		buf.WriteString("\nNo changes\n")
+	}
+	return buf.String()
+}
+
+func diffRepoLines(repos []Repo, marker string) []string {
+	var lines []string
+	for _, repo := range repos {
+		line := fmt.Sprintf("%v %v  %v", marker, repo.Name, repo.AbsPath)
+		if len(repo.SyncDetails) > 0 && marker == "✗" {
+			line += "  (" + strings.Join(repo.SyncDetails, ", ") + ")"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func diffChangeLines(changes []RepoChange) []string {
+	var lines []string
+	for _, change := range changes {
+		lines = append(lines, fmt.Sprintf("%v  %v -> %v", change.Repo.Name, change.From, change.To))
+	}
+	return lines
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	friday := time.Date(2024, 1, 5, 17, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC)
+	from := Output{
+		GeneratedAt: friday,
+		Repos: []Repo{
+			{Name: "a", AbsPath: "/p/a", SyncedWithRemote: true, Branch: "main", LastModified: friday, Author: "x"},
+			{Name: "b", AbsPath: "/p/b", SyncDetails: []string{"uncommitted changes"}, Branch: "main", LastModified: friday, Author: "x"},
+			{Name: "c", AbsPath: "/p/c", SyncedWithRemote: true, Branch: "main", LastModified: friday, Author: "x"},
+			{Name: "old", AbsPath: "/p/old", SyncedWithRemote: true, LastModified: friday},
+		},
+	}
+	to := Output{
+		GeneratedAt: monday,
+		Repos: []Repo{
+			{Name: "a", AbsPath: "/p/a", SyncDetails: []string{"uncommitted changes"}, Branch: "main", LastModified: monday, Author: "x"},
+			{Name: "b", AbsPath: "/p/b", SyncedWithRemote: true, Branch: "main", LastModified: friday, Author: "x"},
+			{Name: "c", AbsPath: "/p/c", SyncedWithRemote: true, Branch: "feature", LastModified: friday, Author: "x"},
+			{Name: "new", AbsPath: "/p/new", SyncedWithRemote: true, LastModified: monday},
+		},
+	}
+	dates := NewDateFormat("datetime", time.UTC)
+	diff := DiffSnapshots(from, to, dates)
+	want := SnapshotDiff{
+		From:          friday,
+		To:            monday,
+		Added:         []Repo{to.Repos[3]},
+		Removed:       []Repo{from.Repos[3]},
+		NewlyUnsynced: []Repo{to.Repos[0]},
+		NewlySynced:   []Repo{to.Repos[1]},
+		Branch:        []RepoChange{{to.Repos[2], "main", "feature"}},
+		Activity:      []RepoChange{{to.Repos[0], "2024-01-05 17:00 by x", "2024-01-08 09:00 by x"}},
+	}
+	if d := cmp.Diff(want, diff); d != "" {
+		t.Errorf("DiffSnapshots mismatch (-want +got):\n%s", d)
+	}
+	wantOutput := `Changes from 2024-01-05 17:00 to 2024-01-08 09:00
+
+Added (1)
+  + new  /p/new
+
+Removed (1)
+  - old  /p/old
+
+Newly not synced (1)
+  ✗ a  /p/a  (uncommitted changes)
+
+Newly synced (1)
+  ✓ b  /p/b
+
+Branch changed (1)
+  c  main -> feature
+
+Activity (1)
+  a  2024-01-05 17:00 by x -> 2024-01-08 09:00 by x
+`
+	if d := cmp.Diff(wantOutput, ConstructDiffOutput(diff, dates)); d != "" {
+		t.Errorf("ConstructDiffOutput mismatch (-want +got):\n%s", d)
+	}
+	if got := ConstructDiffOutput(DiffSnapshots(to, to, dates), dates); got != "Changes from 2024-01-08 09:00 to 2024-01-08 09:00\n\nNo changes\n" {
+		t.Errorf("ConstructDiffOutput for unchanged snapshots = %q", got)
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	names, err := ListSnapshots(dir)
+	if err != nil || len(names) != 0 {
+		t.Fatalf("ListSnapshots of missing dir = %v, %v", names, err)
+	}
+	friday := time.Date(2024, 1, 5, 17, 0, 0, 0, time.UTC)
+	var paths []string
+	for _, at := range []time.Time{friday.AddDate(0, 0, 3), friday.AddDate(0, 0, -1), friday} {
+		output := Output{GeneratedAt: at, Repos: []Repo{{Name: "a", AbsPath: "/p/a", SyncDetails: []string{}}}}
+		path, err := SaveSnapshot(dir, output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	names, err = ListSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantNames := []string{"2024-01-04T170000Z", "2024-01-05T170000Z", "2024-01-08T170000Z"}
+	if d := cmp.Diff(wantNames, names); d != "" {
+		t.Errorf("ListSnapshots mismatch (-want +got):\n%s", d)
+	}
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"2024-01-05T170000Z", paths[2]},
+		{paths[0], paths[0]},
+		// the last snapshot by the end of the day
+		{"2024-01-07", paths[2]},
+		{"2024-01-05", paths[2]},
+		{"2024-01-04", paths[1]},
+	}
+	for _, test := range tests {
+		got, err := ResolveSnapshot(dir, test.arg, time.UTC)
+		if err != nil || got != test.want {
+			t.Errorf("ResolveSnapshot(%q) = %v, %v, want %v", test.arg, got, err, test.want)
+		}
+	}
+	for _, arg := range []string{"2024-01-03", "friday"} {
+		if _, err := ResolveSnapshot(dir, arg, time.UTC); err == nil {
+			t.Errorf("ResolveSnapshot(%q) succeeded, want error", arg)
+		}
+	}
+	output, err := LoadSnapshot(paths[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !output.GeneratedAt.Equal(friday) || len(output.Repos) != 1 || output.Repos[0].AbsPath != "/p/a" {
+		t.Errorf("LoadSnapshot = %+v", output)
+	}
+}
+
+func TestSaveSnapshotSameSecond(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2024, 1, 5, 17, 0, 0, 0, time.UTC)
+	var wantNames []string
+	for i := 1; i <= 11; i++ {
+		output := Output{GeneratedAt: at, Repos: []Repo{{Name: strconv.Itoa(i)}}}
+		path, err := SaveSnapshot(dir, output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		wantNames = append(wantNames, name)
+	}
+	if wantNames[0] != "2024-01-05T170000Z" || wantNames[10] != "2024-01-05T170000Z-11" {
+		t.Errorf("got names %v, want 2024-01-05T170000Z to 2024-01-05T170000Z-11", wantNames)
+	}
+	// the snapshots are listed in the order they were saved
+	names, err := ListSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff(wantNames, names); d != "" {
+		t.Errorf("ListSnapshots mismatch (-want +got):\n%s", d)
+	}
+	path, err := ResolveSnapshot(dir, "2024-01-05", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := LoadSnapshot(path)
+	if err != nil || output.Repos[0].Name != "11" {
+		t.Errorf("got snapshot %+v, %v for 2024-01-05, want the last snapshot", output, err)
+	}
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// statistics for the repos that matched the queries
+type Stats struct {
+	SyncProblems     map[string]int `json:"syncProblems" doc:"number of repos with each sync detail"`
+	Authors          map[string]int `json:"authors" doc:"number of repos where each author made the last commit"`
+	OldestActivity   *time.Time     `json:"oldestActivity,omitempty" doc:"earliest last modified time of the repos, not present without repos"`
+	NewestActivity   *time.Time     `json:"newestActivity,omitempty" doc:"latest last modified time of the repos, not present without repos"`
+	UnpushedCommits  int            `json:"unpushedCommits" doc:"total commits on the current branches that are not on their upstream"`
+	UncommittedFiles int            `json:"uncommittedFiles" doc:"total files with uncommitted changes"`
+	WithoutRemote    int            `json:"withoutRemote" doc:"number of repos without a remote"`
+	FetchFailures    int            `json:"fetchFailures" doc:"number of repos where git fetch failed"`
+}
+
+func computeStats(repos []Repo) Stats {
+	stats := Stats{SyncProblems: map[string]int{}, Authors: map[string]int{}}
+	for _, repo := range repos {
+		for _, detail := range repo.SyncDetails {
+			stats.SyncProblems[detail]++
+		}
+		stats.Authors[valueOrPlaceholder(repo.Author, "(unknown)")]++
+		// repos without a last modified time would always be the oldest
+		if !repo.LastModified.IsZero() {
+			if stats.OldestActivity == nil || repo.LastModified.Before(*stats.OldestActivity) {
+				stats.OldestActivity = &repo.LastModified
+			}
+			if stats.NewestActivity == nil || repo.LastModified.After(*stats.NewestActivity) {
+				stats.NewestActivity = &repo.LastModified
+			}
+		}
+		stats.UnpushedCommits += repo.Ahead
+		stats.UncommittedFiles += repo.UncommittedFiles
+		if repo.Remote == "" {
+			stats.WithoutRemote++
+		}
+		if repo.FetchFailed {
+			stats.FetchFailures++
+		}
+	}
+	return stats
+}
+
+// returns the stats as text to show below the summary in the table output.
+// Dates are shown in the date format dates
+func ConstructStats(stats Stats, dates DateFormat) string {
+	output := "Sync problems:\n" + formatCounts(stats.SyncProblems)
+	output += "Repos per author:\n" + formatCounts(stats.Authors)
+	if stats.OldestActivity != nil {
+		output += fmt.Sprintf("Oldest activity: %v\n", dates.Format(*stats.OldestActivity))
+		output += fmt.Sprintf("Newest activity: %v\n", dates.Format(*stats.NewestActivity))
+	}
+	output += fmt.Sprintf("Unpushed commits: %v\n", stats.UnpushedCommits)
+	output += fmt.Sprintf("Uncommitted files: %v\n", stats.UncommittedFiles)
+	output += fmt.Sprintf("Repos without remote: %v\n", stats.WithoutRemote)
+	output += fmt.Sprintf("Fetch failures: %v\n", stats.FetchFailures)
+	return output
+}
+
+// returns each key with its count on its own indented line, with the highest
+// counts first
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "  none\n"
+	}
+	var keys []string
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), strings.Compare(a, b))
+	})
+	var output string
+	for _, key := range keys {
+		output += fmt.Sprintf("  %v: %v\n", key, counts[key])
+	}
+	return output
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+func TestConstructStats(t *testing.T) {
+	repos := []Repo{
+		{
+			Name:             "a",
+			Author:           "Foo",
+			LastModified:     time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+			SyncDetails:      []string{"uncommitted changes", "branch(es) ahead"},
+			UncommittedFiles: 3,
+			Ahead:            2,
+			Remote:           "git@github.com:foo/a.git",
+		},
+		{
+			Name:         "b",
+			Author:       "Bar",
+			LastModified: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			SyncDetails:  []string{"uncommitted changes"},
+			FetchFailed:  true,
+		},
+		{Name: "c", Author: "Foo"},
+	}
+	want := `Sync problems:
+  uncommitted changes: 2
+  branch(es) ahead: 1
+Repos per author:
+  Foo: 2
+  Bar: 1
+Oldest activity: 2024-01-01
+Newest activity: 2024-01-03
+Unpushed commits: 2
+Uncommitted files: 3
+Repos without remote: 2
+Fetch failures: 1
+`
+	got := ConstructStats(Summarize(repos).Stats, DateFormat{})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
+func TestConstructStatsWithoutRepos(t *testing.T) {
+	want := `Sync problems:
+  none
+Repos per author:
+  none
+Unpushed commits: 0
+Uncommitted files: 0
+Repos without remote: 0
+Fetch failures: 0
+`
+	got := ConstructStats(Summarize(nil).Stats, DateFormat{})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"github.com/clinaresl/table"
+	"strings"
+	"unicode/utf8"
+)
+
+// how the table is rendered for a terminal
+type TableStyle struct {
+	// width of the terminal. Columns are sized from their contents without a
+	// limit when Width is 0
+	Width int
+	// colour unsynced repos and sync details
+	Color bool
+}
+
+// the narrowest a column is shrunk to when the table does not fit the width
+const minColumnWidth = 6
+
+// same as ConstructTable but the columns are sized from their contents to fit
+// style.Width, paths are truncated in the middle instead of wrapped and
+// synced status is shown with a symbol. Each line of a cell is coloured
+// separately so that colours do not run into the borders when a cell wraps
+func ConstructStyledTable(repos []Repo, columnNames []string, style TableStyle, dates DateFormat) (*table.Table, error) {
+	columns := selectColumns(columnNames)
+	cells := make([][][]string, len(repos))
+	for i, repo := range repos {
+		for _, c := range columns {
+			cells[i] = append(cells[i], strings.Split(strings.TrimSuffix(c.styledValue(repo, dates), "\n"), "\n"))
+		}
+	}
+	widths := columnWidths(columns, cells, style.Width)
+
+	var specs []string
+	var headers []any
+	for _, c := range columns {
+		specs = append(specs, strings.ToLower(c.tableSpec[:1]))
+		headers = append(headers, c.tableHeader)
+	}
+	t, err := table.NewTable("| " + strings.Join(specs, " | ") + " |")
+	if err != nil {
+		return nil, err
+	}
+	t.AddThickRule()
+	t.AddRow(headers...)
+	t.AddThickRule()
+	for i, repo := range repos {
+		var values []any
+		for j, c := range columns {
+			var lines []string
+			for _, line := range cells[i][j] {
+				if c.truncate {
+					lines = append(lines, truncateMiddle(line, widths[j]))
+				} else {
+					lines = append(lines, wrapLine(line, widths[j])...)
+				}
+			}
+			if color := cellColor(c, repo); style.Color && color != "" {
+				for k := range lines {
+					if lines[k] != "" {
+						lines[k] = ansiColors[color] + lines[k] + ansiReset
+					}
+				}
+			}
+			values = append(values, strings.Join(lines, "\n"))
+		}
+		t.AddRow(values...)
+		t.AddSingleRule()
+	}
+	return t, nil
+}
+
+// returns the width of each column so that the table fits within width by
+// shrinking the widest columns first. Columns are never narrower than their
+// header or minColumnWidth so the table can still be wider than width
+func columnWidths(columns []column, cells [][][]string, width int) []int {
+	widths := make([]int, len(columns))
+	minWidths := make([]int, len(columns))
+	for j, c := range columns {
+		widths[j] = utf8.RuneCountInString(c.tableHeader)
+		minWidths[j] = max(widths[j], minColumnWidth)
+		for i := range cells {
+			for _, line := range cells[i][j] {
+				widths[j] = max(widths[j], utf8.RuneCountInString(line))
+			}
+		}
+	}
+	if width <= 0 {
+		return widths
+	}
+	// each column has a border and a space on each side
+	tableWidth := 3*len(columns) + 1
+	for _, w := range widths {
+		tableWidth += w
+	}
+	for tableWidth > width {
+		widest := -1
+		for j := range widths {
+			if widths[j] > minWidths[j] && (widest == -1 || widths[j] > widths[widest]) {
+				widest = j
+			}
+		}
+		if widest == -1 {
+			break
+		}
+		widths[widest]--
+		tableWidth--
+	}
+	return widths
+}
+
+// returns the colour for a cell in the styled table or "" for no colour
+func cellColor(c column, repo Repo) string {
+	switch c.name {
+	case "synced":
+		if repo.SyncedWithRemote {
+			return "green"
+		}
+		return "red"
+	case "name":
+		if !repo.SyncedWithRemote {
+			return "red"
+		}
+	case "syncdetails":
+		return "yellow"
+	}
+	return ""
+}
+
+// splits line into lines that are at most width long, breaking at spaces
+// where possible
+func wrapLine(line string, width int) []string {
+	var lines []string
+	var current []rune
+	for _, word := range strings.Fields(line) {
+		runes := []rune(word)
+		if len(current) > 0 && len(current)+1+len(runes) > width {
+			lines = append(lines, string(current))
+			current = nil
+		}
+		if len(current) > 0 {
+			current = append(current, ' ')
+		}
+		current = append(current, runes...)
+		// break words that are longer than the width
+		for len(current) > width {
+			lines = append(lines, string(current[:width]))
+			current = current[width:]
+		}
+	}
+	if len(current) > 0 || len(lines) == 0 {
+		lines = append(lines, string(current))
+	}
+	return lines
+}
+
+// shortens s to width by replacing the middle with an ellipsis so that both
+// the start and the end of a path stay visible
+func truncateMiddle(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 1 {
+		return "…"
+	}
+	// keep more of the end since the end of a path is usually the most useful
+	left := (width - 1) / 2
+	right := width - 1 - left
+	return string(runes[:left]) + "…" + string(runes[len(runes)-right:])
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateMiddle(t *testing.T) {
+	var tests = []struct {
+		input string
+		width int
+		want  string
+	}{
+		{"/home/repos/wheels", 20, "/home/repos/wheels"},
+		{"/home/repos/wheels", 10, "/hom…heels"},
+		{"/home/repos/wheels", 1, "…"},
+	}
+	for _, test := range tests {
+		testname := fmt.Sprintf("%v %v", test.input, test.width)
+		t.Run(testname, func(t *testing.T) {
+			got := truncateMiddle(test.input, test.width)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWrapLine(t *testing.T) {
+	var tests = []struct {
+		input string
+		width int
+		want  []string
+	}{
+		{"- uncommitted changes", 30, []string{"- uncommitted changes"}},
+		{"- uncommitted changes", 14, []string{"- uncommitted", "changes"}},
+		{"stone-drift-moon", 6, []string{"stone-", "drift-", "moon"}},
+		{"", 6, []string{""}},
+	}
+	for _, test := range tests {
+		testname := fmt.Sprintf("%v %v", test.input, test.width)
+		t.Run(testname, func(t *testing.T) {
+			got := wrapLine(test.input, test.width)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStyledTable(t *testing.T) {
+	repos := getInputReposByKey("long")
+	for _, width := range []int{100, 70} {
+		t.Run(fmt.Sprint(width), func(t *testing.T) {
+			table, err := ConstructStyledTable(repos, nil, TableStyle{Width: width}, DateFormat{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range strings.Split(table.String(), "\n") {
+				if utf8.RuneCountInString(line) > width {
+					t.Errorf("line is wider than %v: %v", width, line)
+				}
+			}
+			if strings.Contains(table.String(), "\033[") {
+				t.Errorf("table is coloured without Color")
+			}
+			if !strings.Contains(table.String(), "✗") {
+				t.Errorf("table does not show synced status as a symbol")
+			}
+		})
+	}
+
+	table, err := ConstructStyledTable(repos, []string{"name", "synced"}, TableStyle{Color: true}, DateFormat{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(table.String(), ansiColors["red"]+"✗"+ansiReset) {
+		t.Errorf("unsynced status is not coloured:\n%v", table)
+	}
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// data passed to a template that is executed once for all the repos instead
+// of once for each repo
+type TemplateData struct {
+	Repos   []Repo
+	Root    string
+	Summary string
+}
+
+// ansi escape codes for the colours supported by the color template function
+var ansiColors = map[string]string{
+	"red":     "\033[31m",
+	"green":   "\033[32m",
+	"yellow":  "\033[33m",
+	"blue":    "\033[34m",
+	"magenta": "\033[35m",
+	"cyan":    "\033[36m",
+	"bold":    "\033[1m",
+}
+
+const ansiReset = "\033[0m"
+
+// returns the helper functions of templates. The color function only adds
+// colour when color is true
+func templateFuncs(color bool) template.FuncMap {
+	return template.FuncMap{
+		// formats a time using a go time layout such as "2006-01-02 15:04"
+		"date": func(layout string, t time.Time) string {
+			return t.Format(layout)
+		},
+		// formats a time relative to now such as "3 days ago"
+		"ago": func(t time.Time) string {
+			return formatRelative(t, time.Now())
+		},
+		"join": func(sep string, values []string) string {
+			return strings.Join(values, sep)
+		},
+		// wraps text in the ansi escape codes for a colour
+		"color": func(name string, text any) (string, error) {
+			code, ok := ansiColors[name]
+			if !ok {
+				return "", fmt.Errorf("unknown color %v", name)
+			}
+			if !color {
+				return fmt.Sprint(text), nil
+			}
+			return code + fmt.Sprint(text) + ansiReset, nil
+		},
+	}
+}
+
+// how a template is parsed
+type TemplateOptions struct {
+	// interprets \t, \n and \\ in the text outside of actions, which is
+	// useful for templates passed on the command line since the shell does not
+	// interpret them. Escapes in string literals inside actions are already
+	// interpreted by the template
+	Unescape bool
+	// whether the color function adds colour. Text is left as it is when
+	// false so that the output can be piped to other programs
+	Color bool
+}
+
+// parses a template for the template output with the helper functions
+// available
+func ParseTemplate(text string, options TemplateOptions) (*template.Template, error) {
+	t, err := template.New("output").Funcs(templateFuncs(options.Color)).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+	if options.Unescape {
+		for _, defined := range t.Templates() {
+			if defined.Tree != nil {
+				unescapeText(defined.Tree.Root)
+			}
+		}
+	}
+	return t, nil
+}
+
+// the escape sequences that are commonly used in templates passed on the
+// command line
+var templateEscaper = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n")
+
+// interprets the escape sequences in the text nodes within node
+func unescapeText(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.TextNode:
+		n.Text = []byte(templateEscaper.Replace(string(n.Text)))
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			unescapeText(child)
+		}
+	case *parse.IfNode:
+		unescapeText(n.List)
+		unescapeText(n.ElseList)
+	case *parse.RangeNode:
+		unescapeText(n.List)
+		unescapeText(n.ElseList)
+	case *parse.WithNode:
+		unescapeText(n.List)
+		unescapeText(n.ElseList)
+	}
+}
+
+// executes t once for each repo with the repo as data, putting the output for
+// each repo on its own line. When list is true, t is executed once with
+// TemplateData instead
+func ConstructTemplateOutput(t *template.Template, repos []Repo, root string, summary string, list bool) (string, error) {
+	var buf bytes.Buffer
+	if list {
+		data := TemplateData{Repos: repos, Root: root, Summary: summary}
+		err := t.Execute(&buf, data)
+		if err != nil {
+			return "", fmt.Errorf("error executing template: %v", err)
+		}
+		return buf.String(), nil
+	}
+	for _, repo := range repos {
+		err := t.Execute(&buf, repo)
+		if err != nil {
+			return "", fmt.Errorf("error executing template for %v: %v", repo.AbsPath, err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteString("\n")
+		}
+	}
+	return buf.String(), nil
+}
+
+// returns how long ago t was compared to now in the largest whole unit such
+// as "3 days ago" or "2 months ago"
+func formatRelative(t time.Time, now time.Time) string {
+	d := now.Sub(t)
+	suffix := "ago"
+	if d < 0 {
+		d = -d
+		suffix = "from now"
+	}
+	day := 24 * time.Hour
+	var count int
+	var unit string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		count, unit = int(d/time.Minute), "minute"
+	case d < day:
+		count, unit = int(d/time.Hour), "hour"
+	case d < 7*day:
+		count, unit = int(d/day), "day"
+	case d < 30*day:
+		count, unit = int(d/(7*day)), "week"
+	case d < 365*day:
+		count, unit = int(d/(30*day)), "month"
+	default:
+		count, unit = int(d/(365*day)), "year"
+	}
+	if count != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %v %v", count, unit, suffix)
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+func TestTemplateOutput(t *testing.T) {
+	repos := getInputReposByKey("long")
+	repos[1].SyncedWithRemote = true
+	var tests = []struct {
+		text string
+		list bool
+		want string
+	}{
+		{
+			"{{.Name}}\t{{if not .SyncedWithRemote}}DIRTY{{end}}",
+			false,
+			"blink-frost-dune-glimmer\tDIRTY\nstone-drift-moon-sparkle-breeze\t\n",
+		},
+		{
+			`{{date "02/01/2006" .LastModified}} {{join "; " .SyncDetails}}`,
+			false,
+			"01/01/2024 uncommitted changes; untracked branch(es)\n" +
+				"02/01/2024 uncommitted changes; untracked branch(es); branch(es) ahead\n",
+		},
+		{
+			`{{color "red" .Name}}{{"\n"}}`,
+			false,
+			"\033[31mblink-frost-dune-glimmer\033[0m\n\033[31mstone-drift-moon-sparkle-breeze\033[0m\n",
+		},
+		{
+			"{{range .Repos}}{{.Name}},{{end}}\n{{.Root}}: {{.Summary}}\n",
+			true,
+			"blink-frost-dune-glimmer,stone-drift-moon-sparkle-breeze,\n/home/repos: 2 repos\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			tmpl, err := ParseTemplate(test.text, TemplateOptions{Color: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ConstructTemplateOutput(tmpl, repos, "/home/repos", "2 repos", test.list)
+			if diff := cmp.Diff(test.want, got); diff != "" || err != nil {
+				t.Errorf("-want +got:\n%s\n%v", diff, err)
+			}
+		})
+	}
+}
+
+func TestTemplateUnescape(t *testing.T) {
+	// escapes in the text are interpreted while escapes in string literals
+	// are left to the template, which interprets them once
+	text := `{{.Name}}:\n  {{join "\n  " .SyncDetails}}{{if .SyncDetails}}\t\\{{end}}`
+	tmpl, err := ParseTemplate(text, TemplateOptions{Unescape: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ConstructTemplateOutput(tmpl, getInputReposByKey("long")[:1], "", "", false)
+	want := "blink-frost-dune-glimmer:\n  uncommitted changes\n  untracked branch(es)\t\\\n"
+	if diff := cmp.Diff(want, got); diff != "" || err != nil {
+		t.Errorf("-want +got:\n%s\n%v", diff, err)
+	}
+}
+
+func TestTemplateColorDisabled(t *testing.T) {
+	tmpl, _ := ParseTemplate(`{{color "red" .Name}}`, TemplateOptions{Color: false})
+	got, err := ConstructTemplateOutput(tmpl, getInputReposByKey("short")[:1], "", "", false)
+	if got != "wheels\n" || err != nil {
+		t.Errorf("got (%q, %v)\nwant (%q, %v)", got, err, "wheels\n", nil)
+	}
+}
+
+func TestTemplateError(t *testing.T) {
+	wantE := fmt.Errorf("invalid template: template: output:1: unclosed action")
+	_, gotE := ParseTemplate("{{.Name", TemplateOptions{})
+	if gotE == nil || gotE.Error() != wantE.Error() {
+		t.Errorf(
+			"got (%v)\nwant (%v)",
+			gotE, wantE,
+		)
+	}
+}
+
+func TestFormatRelative(t *testing.T) {
+	now, _ := time.Parse(time.DateTime, "2024-06-15 12:00:00")
+	var tests = []struct {
+		t    time.Time
+		want string
+	}{
+		{now.Add(-30 * time.Second), "just now"},
+		{now.Add(-time.Minute), "1 minute ago"},
+		{now.Add(-5 * time.Hour), "5 hours ago"},
+		{now.Add(-3 * 24 * time.Hour), "3 days ago"},
+		{now.Add(-15 * 24 * time.Hour), "2 weeks ago"},
+		{now.Add(-65 * 24 * time.Hour), "2 months ago"},
+		{now.Add(-800 * 24 * time.Hour), "2 years ago"},
+		{now.Add(2 * 24 * time.Hour), "2 days from now"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			got := formatRelative(test.t, now)
+			if got != test.want {
+				t.Errorf("got %v want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// a directory in the tree output along with the counts for all the repos
+// under it
+type treeNode struct {
+	name     string
+	dirs     []*treeNode
+	repos    []Repo
+	total    int
+	unsynced int
+}
+
+// returns the child directory called name, adding it if it does not exist
+func (n *treeNode) dir(name string) *treeNode {
+	i := slices.IndexFunc(n.dirs, func(d *treeNode) bool { return d.name == name })
+	if i == -1 {
+		n.dirs = append(n.dirs, &treeNode{name: name})
+		i = len(n.dirs) - 1
+	}
+	return n.dirs[i]
+}
+
+// returns the repos arranged by the directories in their path relative to
+// root followed by the summary. Each directory shows how many of the repos
+// under it are not synced. When collapseSynced is true, the repos under
+// directories without unsynced repos are not shown. Directories and repos are
+// sorted by name within each directory
+func ConstructTreeOutput(repos []Repo, root string, collapseSynced bool, summary string) string {
+	tree := &treeNode{name: root}
+	for _, repo := range repos {
+		node := tree
+		node.addCounts(repo)
+		// the root itself is a repo when its path is "."
+		dirs := strings.Split(repo.Path, "/")
+		for _, name := range dirs[:len(dirs)-1] {
+			node = node.dir(name)
+			node.addCounts(repo)
+		}
+		node.repos = append(node.repos, repo)
+	}
+	var buf strings.Builder
+	buf.WriteString(tree.name + " " + tree.status() + "\n")
+	tree.write(&buf, "", collapseSynced)
+	return buf.String() + "\n" + summary + "\n"
+}
+
+func (n *treeNode) addCounts(repo Repo) {
+	n.total++
+	if !repo.SyncedWithRemote {
+		n.unsynced++
+	}
+}
+
+func (n *treeNode) status() string {
+	if n.unsynced == 0 {
+		return fmt.Sprintf("(%v synced)", n.total)
+	}
+	return fmt.Sprintf("(%v of %v unsynced)", n.unsynced, n.total)
+}
+
+// writes the directories and repos under n with each line starting with
+// prefix to draw the branches of the tree
+func (n *treeNode) write(buf *strings.Builder, prefix string, collapseSynced bool) {
+	type entry struct {
+		name string
+		line string
+		dir  *treeNode
+	}
+	var entries []entry
+	for _, d := range n.dirs {
+		entries = append(entries, entry{d.name, d.name + "/ " + d.status(), d})
+	}
+	for _, repo := range n.repos {
+		status := "synced"
+		if !repo.SyncedWithRemote {
+			status = "not synced"
+			if len(repo.SyncDetails) > 0 {
+				status += ": " + strings.Join(repo.SyncDetails, ", ")
+			}
+		}
+		entries = append(entries, entry{repo.Name, repo.Name + " - " + status, nil})
+	}
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		return strings.Compare(a.name, b.name)
+	})
+	for i, e := range entries {
+		branch, childPrefix := "├── ", "│   "
+		if i == len(entries)-1 {
+			branch, childPrefix = "└── ", "    "
+		}
+		buf.WriteString(prefix + branch + e.line + "\n")
+		if e.dir != nil && !(collapseSynced && e.dir.unsynced == 0) {
+			e.dir.write(buf, prefix+childPrefix, collapseSynced)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestTreeOutput(t *testing.T) {
+	repos := []Repo{
+		{Name: "wheels", Path: "org/team-b/wheels", SyncedWithRemote: true},
+		{Name: "engine", Path: "org/team-a/engine", SyncedWithRemote: false, SyncDetails: []string{"uncommitted changes"}},
+		{Name: "brakes", Path: "org/team-a/brakes", SyncedWithRemote: true},
+		{Name: "notes", Path: "notes", SyncedWithRemote: false},
+	}
+	var tests = []struct {
+		collapseSynced bool
+		want           string
+	}{
+		{
+			false,
+			`/home/repos (2 of 4 unsynced)
+├── notes - not synced
+└── org/ (1 of 3 unsynced)
+    ├── team-a/ (1 of 2 unsynced)
+    │   ├── brakes - synced
+    │   └── engine - not synced: uncommitted changes
+    └── team-b/ (1 synced)
+        └── wheels - synced
+
+summary
+`,
+		},
+		{
+			true,
+			`/home/repos (2 of 4 unsynced)
+├── notes - not synced
+└── org/ (1 of 3 unsynced)
+    ├── team-a/ (1 of 2 unsynced)
+    │   ├── brakes - synced
+    │   └── engine - not synced: uncommitted changes
+    └── team-b/ (1 synced)
+
+summary
+`,
+		},
+	}
+	for _, test := range tests {
+		testname := fmt.Sprintf("collapse synced %v", test.collapseSynced)
+		t.Run(testname, func(t *testing.T) {
+			got := ConstructTreeOutput(repos, "/home/repos", test.collapseSynced, "summary")
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
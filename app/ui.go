@@ -0,0 +1,375 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// actions that the caller of UI.HandleKey runs on the selected repo
+const (
+	UIActionNone    = ""
+	UIActionQuit    = "quit"
+	UIActionFetch   = "fetch"
+	UIActionPull    = "pull"
+	UIActionShell   = "shell"
+	UIActionCopy    = "copy"
+	UIActionRefresh = "refresh"
+)
+
+// the help shown at the bottom of the ui
+const uiHelp = "↑↓ move  / filter  f fetch  p pull  s shell  y copy path  r refresh  q quit"
+
+const (
+	ansiReverse   = "\033[7m"
+	ansiClearLine = "\033[K"
+)
+
+// the state of the interactive terminal ui. It only decides what is shown and
+// which action a key is for, running the actions and reading keys from the
+// terminal is left to the caller
+type UI struct {
+	Root string
+	// how the last modified dates of the repos are shown
+	Dates DateFormat
+	// shown below the list until the next key is pressed
+	Message string
+	repos   []Repo
+	// only repos with the filter in their name, path or author are shown
+	filter    string
+	filtering bool
+	// index of the selected repo in the visible repos
+	selected int
+	// index of the first visible repo shown in the list
+	top int
+	// rows in the list the last time the ui was rendered
+	listHeight int
+}
+
+func NewUI(repos []Repo, root string, dates DateFormat) *UI {
+	return &UI{Root: root, Dates: dates, repos: repos, listHeight: 1}
+}
+
+// returns the repos that match the filter
+func (u *UI) Visible() []Repo {
+	if u.filter == "" {
+		return u.repos
+	}
+	filter := strings.ToLower(u.filter)
+	var visible []Repo
+	for _, repo := range u.repos {
+		for _, value := range []string{repo.Name, repo.Path, repo.Author} {
+			if strings.Contains(strings.ToLower(value), filter) {
+				visible = append(visible, repo)
+				break
+			}
+		}
+	}
+	return visible
+}
+
+// returns the selected repo. ok is false if no repo is visible
+func (u *UI) Selected() (repo Repo, ok bool) {
+	visible := u.Visible()
+	if len(visible) == 0 {
+		return Repo{}, false
+	}
+	return visible[u.selected], true
+}
+
+// replaces the repos, keeping the repo with the same path selected if it is
+// still visible
+func (u *UI) SetRepos(repos []Repo) {
+	selected, ok := u.Selected()
+	u.repos = repos
+	if ok {
+		i := slices.IndexFunc(u.Visible(), func(repo Repo) bool { return repo.Path == selected.Path })
+		if i != -1 {
+			u.selected = i
+		}
+	}
+	u.clampSelection()
+}
+
+// updates the state for key and returns the action to run. Keys are named as
+// returned by ParseKeys
+func (u *UI) HandleKey(key string) string {
+	u.Message = ""
+	if u.filtering {
+		switch key {
+		case "enter":
+			u.filtering = false
+		case "esc":
+			u.filtering = false
+			u.setFilter("")
+		case "backspace":
+			runes := []rune(u.filter)
+			if len(runes) > 0 {
+				u.setFilter(string(runes[:len(runes)-1]))
+			}
+		case "ctrl+c":
+			return UIActionQuit
+		default:
+			if utf8.RuneCountInString(key) == 1 {
+				u.setFilter(u.filter + key)
+			}
+		}
+		return UIActionNone
+	}
+	switch key {
+	case "up", "k":
+		u.move(-1)
+	case "down", "j":
+		u.move(1)
+	case "pgup":
+		u.move(-u.listHeight)
+	case "pgdown":
+		u.move(u.listHeight)
+	case "home", "g":
+		u.move(-len(u.repos))
+	case "end", "G":
+		u.move(len(u.repos))
+	case "/":
+		u.filtering = true
+	case "esc":
+		u.setFilter("")
+	case "f":
+		return UIActionFetch
+	case "p":
+		return UIActionPull
+	case "s":
+		return UIActionShell
+	case "y":
+		return UIActionCopy
+	case "r":
+		return UIActionRefresh
+	case "q", "ctrl+c":
+		return UIActionQuit
+	}
+	return UIActionNone
+}
+
+func (u *UI) setFilter(filter string) {
+	u.filter = filter
+	u.selected = 0
+	u.top = 0
+}
+
+func (u *UI) move(n int) {
+	u.selected += n
+	u.clampSelection()
+}
+
+// keeps the selection within the visible repos and scrolls the list so that
+// the selection is shown
+func (u *UI) clampSelection() {
+	visible := len(u.Visible())
+	u.selected = max(min(u.selected, visible-1), 0)
+	if u.selected < u.top {
+		u.top = u.selected
+	}
+	if u.selected >= u.top+u.listHeight {
+		u.top = u.selected - u.listHeight + 1
+	}
+	u.top = max(min(u.top, visible-u.listHeight), 0)
+}
+
+// returns the screen for a terminal of width by height. The list of repos is
+// followed by info for the selected repo, which can be nil while it is not
+// known. Each line clears the rest of the line so that the screen can be
+// redrawn without clearing it first
+func (u *UI) Render(width int, height int, info *RepoInfo) string {
+	// header, separator, filter or message and help
+	const fixedLines = 4
+	detailHeight := (height - fixedLines) / 3
+	u.listHeight = max(height-fixedLines-detailHeight, 1)
+	u.clampSelection()
+	visible := u.Visible()
+	var lines []string
+
+	unsynced := 0
+	for _, repo := range visible {
+		if !repo.SyncedWithRemote {
+			unsynced++
+		}
+	}
+	header := fmt.Sprintf("repocheck %v  %v repos, %v not synced", u.Root, len(visible), unsynced)
+	if len(visible) != len(u.repos) {
+		header = fmt.Sprintf("repocheck %v  %v of %v repos, %v not synced", u.Root, len(visible), len(u.repos), unsynced)
+	}
+	lines = append(lines, fitLine(header, width))
+
+	nameWidth, branchWidth := 4, 6
+	for _, repo := range visible {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(repo.Name))
+		branchWidth = max(branchWidth, utf8.RuneCountInString(repo.Branch))
+	}
+	nameWidth, branchWidth = min(nameWidth, 30), min(branchWidth, 20)
+	for row := 0; row < u.listHeight; row++ {
+		i := u.top + row
+		if i >= len(visible) {
+			lines = append(lines, "")
+			continue
+		}
+		repo := visible[i]
+		status := "✓ synced"
+		if !repo.SyncedWithRemote {
+			status = "✗ " + strings.Join(repo.SyncDetails, ", ")
+		}
+		line := fmt.Sprintf("  %v  %v  %v  %v",
+			padRight(truncateMiddle(repo.Name, nameWidth), nameWidth),
+			padRight(truncateMiddle(repo.Branch, branchWidth), branchWidth),
+			padRight(u.Dates.Format(repo.LastModified), 10),
+			status,
+		)
+		line = fitLine(line, width)
+		if i == u.selected {
+			line = ansiReverse + padRight(line, width) + ansiReset
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, strings.Repeat("─", max(width, 0)))
+	details := u.details(info)
+	for row := 0; row < detailHeight; row++ {
+		if row < len(details) {
+			lines = append(lines, fitLine(details[row], width))
+		} else {
+			lines = append(lines, "")
+		}
+	}
+
+	switch {
+	case u.filtering:
+		lines = append(lines, fitLine("/"+u.filter, width))
+	case u.Message != "":
+		lines = append(lines, fitLine(u.Message, width))
+	case u.filter != "":
+		lines = append(lines, fitLine("filter: "+u.filter+"  (esc clears)", width))
+	default:
+		lines = append(lines, "")
+	}
+	lines = append(lines, fitLine(uiHelp, width))
+	return strings.Join(lines, ansiClearLine+"\r\n") + ansiClearLine
+}
+
+// returns the lines of the detail pane for the selected repo
+func (u *UI) details(info *RepoInfo) []string {
+	repo, ok := u.Selected()
+	if !ok {
+		return []string{"no repos match the filter"}
+	}
+	lines := []string{fmt.Sprintf("%v  %v", repo.AbsPath, repo.Remote)}
+	if info == nil {
+		return append(lines, "loading...")
+	}
+	lines = append(lines, "Branches:")
+	lines = append(lines, indent(info.Branches)...)
+	if len(info.StatusFiles) > 0 {
+		lines = append(lines, "Uncommitted changes:")
+		lines = append(lines, indent(info.StatusFiles)...)
+	}
+	if len(info.RecentCommits) > 0 {
+		lines = append(lines, "Recent commits:")
+		lines = append(lines, indent(info.RecentCommits)...)
+	}
+	return lines
+}
+
+func indent(lines []string) []string {
+	indented := make([]string, len(lines))
+	for i, line := range lines {
+		indented[i] = "  " + line
+	}
+	return indented
+}
+
+// shortens line to width, replacing characters that would move the cursor
+// such as tabs so that the screen layout is kept
+func fitLine(line string, width int) string {
+	line = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, line)
+	runes := []rune(line)
+	if len(runes) <= width {
+		return line
+	}
+	if width <= 1 {
+		return string(runes[:max(width, 0)])
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-utf8.RuneCountInString(s), 0))
+}
+
+// returns the names of the keys in b, which was read from a terminal in raw
+// mode. Printable characters are returned as they are and other keys by name
+// such as "up", "enter" or "ctrl+c". Unknown escape sequences are dropped
+func ParseKeys(b []byte) []string {
+	var keys []string
+	s := string(b)
+	for len(s) > 0 {
+		if strings.HasPrefix(s, "\x1b") {
+			key, n := parseEscape(s)
+			if key != "" {
+				keys = append(keys, key)
+			}
+			s = s[n:]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		switch r {
+		case '\r', '\n':
+			keys = append(keys, "enter")
+		case '\t':
+			keys = append(keys, "tab")
+		case 127, '\b':
+			keys = append(keys, "backspace")
+		case 3:
+			keys = append(keys, "ctrl+c")
+		default:
+			if unicode.IsPrint(r) {
+				keys = append(keys, string(r))
+			}
+		}
+	}
+	return keys
+}
+
+// escape sequences sent by terminals for keys, with and without application
+// cursor mode
+var escapeKeys = map[string]string{
+	"\x1b[A": "up", "\x1bOA": "up",
+	"\x1b[B": "down", "\x1bOB": "down",
+	"\x1b[H": "home", "\x1bOH": "home", "\x1b[1~": "home",
+	"\x1b[F": "end", "\x1bOF": "end", "\x1b[4~": "end",
+	"\x1b[5~": "pgup",
+	"\x1b[6~": "pgdown",
+}
+
+// returns the key for the escape sequence at the start of s and its length
+func parseEscape(s string) (string, int) {
+	for seq, key := range escapeKeys {
+		if strings.HasPrefix(s, seq) {
+			return key, len(seq)
+		}
+	}
+	// a sequence that is not known is skipped up to its final byte
+	if len(s) > 1 && (s[1] == '[' || s[1] == 'O') {
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return "", i + 1
+			}
+		}
+		return "", len(s)
+	}
+	return "esc", 1
+}
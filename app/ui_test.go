@@ -0,0 +1,109 @@
+package app
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseKeys(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  []string
+	}{
+		{"jk", []string{"j", "k"}},
+		{"\x1b[A\x1b[B", []string{"up", "down"}},
+		{"\x1bOA", []string{"up"}},
+		{"\x1b[5~\x1b[6~", []string{"pgup", "pgdown"}},
+		{"\x1b", []string{"esc"}},
+		{"\x1b[1;5C", nil},
+		{"ä\r\x7f\x03", []string{"ä", "enter", "backspace", "ctrl+c"}},
+	}
+	for _, test := range tests {
+		got := ParseKeys([]byte(test.input))
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("%q: -want +got:\n%s", test.input, diff)
+		}
+	}
+}
+
+func uiTestRepos() []Repo {
+	return []Repo{
+		{Name: "api", Path: "work/api", Author: "Foo Bar", Branch: "main", SyncedWithRemote: true},
+		{Name: "cli", Path: "work/cli", Author: "Baz", Branch: "dev", SyncDetails: []string{"uncommitted changes"}},
+		{Name: "dotfiles", Path: "dotfiles", Author: "Foo Bar", Branch: "main", SyncedWithRemote: true},
+	}
+}
+
+func TestUIHandleKey(t *testing.T) {
+	var tests = []struct {
+		keys         []string
+		wantSelected string
+		wantVisible  int
+		wantAction   string
+	}{
+		{[]string{"down", "down", "down"}, "dotfiles", 3, UIActionNone},
+		{[]string{"end", "up"}, "cli", 3, UIActionNone},
+		{[]string{"/", "w", "o", "r", "k", "enter", "down"}, "cli", 2, UIActionNone},
+		// the filter matches the author
+		{[]string{"/", "b", "a", "z"}, "cli", 1, UIActionNone},
+		{[]string{"/", "x", "backspace", "f", "o", "o", "esc"}, "api", 3, UIActionNone},
+		// keys for actions are typed into the filter while filtering
+		{[]string{"/", "q"}, "", 0, UIActionNone},
+		{[]string{"down", "p"}, "cli", 3, UIActionPull},
+		{[]string{"q"}, "api", 3, UIActionQuit},
+	}
+	for _, test := range tests {
+		ui := NewUI(uiTestRepos(), "/home/user", DateFormat{})
+		var action string
+		for _, key := range test.keys {
+			action = ui.HandleKey(key)
+		}
+		selected, _ := ui.Selected()
+		if selected.Name != test.wantSelected || len(ui.Visible()) != test.wantVisible || action != test.wantAction {
+			t.Errorf("%v: got %v selected, %v visible and action %q, want %v, %v and %q",
+				test.keys, selected.Name, len(ui.Visible()), action, test.wantSelected, test.wantVisible, test.wantAction)
+		}
+	}
+}
+
+func TestUISetReposKeepsSelection(t *testing.T) {
+	ui := NewUI(uiTestRepos(), "/home/user", DateFormat{})
+	ui.HandleKey("down")
+	repos := uiTestRepos()
+	// the selected repo moves to the end after sorting
+	repos[1], repos[2] = repos[2], repos[1]
+	ui.SetRepos(repos)
+	selected, _ := ui.Selected()
+	if selected.Name != "cli" {
+		t.Errorf("got %v selected, want cli", selected.Name)
+	}
+}
+
+func TestUIRender(t *testing.T) {
+	ui := NewUI(uiTestRepos(), "/home/user", NewDateFormat("date", time.UTC))
+	ui.HandleKey("down")
+	info := &RepoInfo{
+		Branches:    []string{"* dev -> origin/dev"},
+		StatusFiles: []string{" M main.go"},
+	}
+	got := strings.ReplaceAll(ui.Render(60, 12, info), ansiClearLine, "")
+	want := strings.Join([]string{
+		"repocheck /home/user  3 repos, 1 not synced",
+		"  api       main    0001-01-01  ✓ synced",
+		ansiReverse + padRight("  cli       dev     0001-01-01  ✗ uncommitted changes", 60) + ansiReset,
+		"  dotfiles  main    0001-01-01  ✓ synced",
+		"",
+		"",
+		"",
+		strings.Repeat("─", 60),
+		"  ",
+		"Branches:",
+		"",
+		"↑↓ move  / filter  f fetch  p pull  s shell  y copy path  r…",
+	}, "\r\n")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
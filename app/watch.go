@@ -0,0 +1,175 @@
+package app
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// how long the watcher waits for more changes after a change before
+// reporting, so that a command that writes many files is reported once
+const watchDebounce = 200 * time.Millisecond
+
+// the ways a Watcher can find out about changes
+const (
+	WatchEvents  = "events"
+	WatchPolling = "polling"
+)
+
+// reports the repos whose working tree or refs changed. Changes to other files
+// in .git such as the index are ignored since git rewrites them while reading
+// the status of the repo
+type Watcher struct {
+	// receives the paths of the repos that changed, relative to the root the
+	// repos were found in like Repo.Path
+	Changes <-chan []string
+	// WatchEvents when the watcher is told about changes by the operating
+	// system or WatchPolling when it checks the repos every interval
+	Mode     string
+	changed  chan string
+	done     chan struct{}
+	stop     func() error
+	stopOnce sync.Once
+}
+
+// starts watching repos. File system events are used where they are supported
+// and polling every pollInterval is used otherwise or when forcePolling is true
+func NewWatcher(repos []Repo, pollInterval time.Duration, forcePolling bool) *Watcher {
+	changes := make(chan []string)
+	w := &Watcher{
+		Changes: changes,
+		changed: make(chan string),
+		done:    make(chan struct{}),
+	}
+	var err error
+	if !forcePolling {
+		w.stop, err = watchEvents(repos, w.changed, w.done)
+	}
+	if forcePolling || err != nil {
+		w.Mode = WatchPolling
+		w.stop = watchPolling(repos, pollInterval, w.changed, w.done)
+	} else {
+		w.Mode = WatchEvents
+	}
+	go debounce(w.changed, changes, w.done)
+	return w
+}
+
+// stops watching. Changes is not sent to after Close returns
+func (w *Watcher) Close() error {
+	var err error
+	w.stopOnce.Do(func() {
+		close(w.done)
+		err = w.stop()
+	})
+	return err
+}
+
+// collects the paths from changed until no path has been received for
+// watchDebounce and then sends them to changes without duplicates
+func debounce(changed <-chan string, changes chan<- []string, done <-chan struct{}) {
+	var paths []string
+	seen := map[string]bool{}
+	var timer <-chan time.Time
+	for {
+		select {
+		case path := <-changed:
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+			timer = time.After(watchDebounce)
+		case <-timer:
+			select {
+			case changes <- paths:
+			case <-done:
+				return
+			}
+			paths = nil
+			seen = map[string]bool{}
+			timer = nil
+		case <-done:
+			return
+		}
+	}
+}
+
+// sends the path of each repo whose fingerprint changed since the last
+// interval
+func watchPolling(repos []Repo, interval time.Duration, changed chan<- string, done <-chan struct{}) func() error {
+	fingerprints := make([]fingerprint, len(repos))
+	for i, repo := range repos {
+		fingerprints[i] = repoFingerprint(repo.AbsPath)
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+			for i, repo := range repos {
+				current := repoFingerprint(repo.AbsPath)
+				if current == fingerprints[i] {
+					continue
+				}
+				fingerprints[i] = current
+				select {
+				case changed <- repo.Path:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+	return func() error { return nil }
+}
+
+// changes when a file that is watched is added, removed or modified. Removing
+// a file changes the modification time of its directory
+type fingerprint struct {
+	files  int
+	latest time.Time
+}
+
+// returns the fingerprint of the working tree, HEAD and the refs of the repo
+// at absPath
+func repoFingerprint(absPath string) fingerprint {
+	var f fingerprint
+	add := func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// the file was removed while walking
+			return nil
+		}
+		if d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		f.files++
+		if info.ModTime().After(f.latest) {
+			f.latest = info.ModTime()
+		}
+		return nil
+	}
+	filepath.WalkDir(absPath, add)
+	gitDir := filepath.Join(absPath, ".git")
+	filepath.WalkDir(filepath.Join(gitDir, "refs"), add)
+	for _, name := range watchedGitFiles {
+		info, err := os.Stat(filepath.Join(gitDir, name))
+		if err == nil {
+			add(name, fs.FileInfoToDirEntry(info), nil)
+		}
+	}
+	return f
+}
+
+// the files directly in .git that change the sync status of a repo. Changes
+// in .git/refs are also watched
+var watchedGitFiles = []string{"HEAD", "packed-refs"}
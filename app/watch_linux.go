@@ -0,0 +1,177 @@
+//go:build linux
+
+package app
+
+import (
+	"golang.org/x/sys/unix"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// the events that change the working tree or refs of a repo
+const inotifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MODIFY | unix.IN_ATTRIB |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_CLOSE_WRITE
+
+// a directory watched with inotify
+type watchedDir struct {
+	// path of the repo relative to the root like Repo.Path
+	repoPath string
+	absPath  string
+	// whether the directory is the .git directory of the repo, where only
+	// watchedGitFiles are of interest
+	gitDir bool
+}
+
+type inotifyWatcher struct {
+	// used to add watches since calling file.Fd would make reads blocking
+	fd      int
+	file    *os.File
+	mu      sync.Mutex
+	dirs    map[int32]watchedDir
+	repos   []Repo
+	changed chan<- string
+	done    <-chan struct{}
+}
+
+// watches the working tree and refs of each repo with inotify and sends the
+// path of a repo to changed when it changes. Every directory needs its own
+// watch so an error is returned when there are more directories than
+// fs.inotify.max_user_watches allows
+func watchEvents(repos []Repo, changed chan<- string, done <-chan struct{}) (func() error, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	// a non-blocking file is read through the runtime poller so that closing
+	// the file stops a read that is waiting for events
+	w := &inotifyWatcher{
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		dirs:    map[int32]watchedDir{},
+		repos:   repos,
+		changed: changed,
+		done:    done,
+	}
+	for _, repo := range repos {
+		err = w.addTree(repo.Path, repo.AbsPath)
+		if err == nil {
+			err = w.addGitDir(repo.Path, filepath.Join(repo.AbsPath, ".git"))
+		}
+		if err != nil {
+			w.file.Close()
+			return nil, err
+		}
+	}
+	go w.read()
+	return w.file.Close, nil
+}
+
+// watches dir and every directory under it other than .git
+func (w *inotifyWatcher) addTree(repoPath string, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// the directory was removed while walking
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		return w.add(watchedDir{repoPath: repoPath, absPath: path})
+	})
+}
+
+// watches the .git directory for changes to watchedGitFiles and every
+// directory under .git/refs
+func (w *inotifyWatcher) addGitDir(repoPath string, gitDir string) error {
+	err := w.add(watchedDir{repoPath: repoPath, absPath: gitDir, gitDir: true})
+	if err != nil {
+		return err
+	}
+	return w.addTree(repoPath, filepath.Join(gitDir, "refs"))
+}
+
+func (w *inotifyWatcher) add(dir watchedDir) error {
+	wd, err := unix.InotifyAddWatch(w.fd, dir.absPath, inotifyMask|unix.IN_ONLYDIR)
+	if err != nil {
+		return &fs.PathError{Op: "watch", Path: dir.absPath, Err: err}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.dirs[int32(wd)] = dir
+	return nil
+}
+
+// reads events until the inotify file is closed
+func (w *inotifyWatcher) read() {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			name := strings.TrimRight(string(buf[nameStart:nameStart+int(event.Len)]), "\x00")
+			offset = nameStart + int(event.Len)
+			if !w.handle(event, name) {
+				return
+			}
+		}
+	}
+}
+
+// sends the repo that the event is for to changed. Returns false when the
+// watcher is closed
+func (w *inotifyWatcher) handle(event *unix.InotifyEvent, name string) bool {
+	var repoPaths []string
+	if event.Mask&unix.IN_Q_OVERFLOW != 0 {
+		// events were dropped so any repo may have changed
+		for _, repo := range w.repos {
+			repoPaths = append(repoPaths, repo.Path)
+		}
+	}
+	w.mu.Lock()
+	dir, ok := w.dirs[event.Wd]
+	if event.Mask&unix.IN_IGNORED != 0 {
+		// the directory was removed
+		delete(w.dirs, event.Wd)
+	}
+	w.mu.Unlock()
+	if ok && !ignoreEvent(dir, name) {
+		repoPaths = append(repoPaths, dir.repoPath)
+		if event.Mask&unix.IN_CREATE != 0 && event.Mask&unix.IN_ISDIR != 0 && !dir.gitDir {
+			// errors are ignored since the directory can be removed before
+			// it is watched
+			w.addTree(dir.repoPath, filepath.Join(dir.absPath, name))
+		}
+	}
+	for _, repoPath := range repoPaths {
+		select {
+		case w.changed <- repoPath:
+		case <-w.done:
+			return false
+		}
+	}
+	return true
+}
+
+// returns whether an event for the file name in dir does not change the repo.
+// Lock files are written by git while it updates the index and refs
+func ignoreEvent(dir watchedDir, name string) bool {
+	if name == ".git" || strings.HasSuffix(name, ".lock") {
+		return true
+	}
+	if dir.gitDir {
+		return !slices.Contains(watchedGitFiles, name)
+	}
+	return false
+}
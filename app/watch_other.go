@@ -0,0 +1,11 @@
+//go:build !linux
+
+package app
+
+import "errors"
+
+// file system events are only supported on linux so other platforms always
+// fall back to polling
+func watchEvents(repos []Repo, changed chan<- string, done <-chan struct{}) (func() error, error) {
+	return nil, errors.New("file system events are not supported on this platform")
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	for _, forcePolling := range []bool{false, true} {
+		root := t.TempDir()
+		var repos []Repo
+		for _, name := range []string{"a", "b"} {
+			err := os.MkdirAll(filepath.Join(root, name, ".git", "refs", "heads"), 0755)
+			if err != nil {
+				t.Fatal(err)
+			}
+			repos = append(repos, Repo{Name: name, Path: name, AbsPath: filepath.Join(root, name)})
+		}
+		w := NewWatcher(repos, 50*time.Millisecond, forcePolling)
+		// the modification times of the files have to differ for polling
+		time.Sleep(20 * time.Millisecond)
+		// changes to the index are ignored
+		writeFile(t, filepath.Join(root, "a", ".git", "index"))
+		writeFile(t, filepath.Join(root, "b", ".git", "refs", "heads", "main"))
+		select {
+		case got := <-w.Changes:
+			if len(got) != 1 || got[0] != "b" {
+				t.Errorf("%v: got changes %v, want [b]", w.Mode, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("%v: no changes reported", w.Mode)
+		}
+		writeFile(t, filepath.Join(root, "a", "file"))
+		select {
+		case got := <-w.Changes:
+			if len(got) != 1 || got[0] != "a" {
+				t.Errorf("%v: got changes %v, want [a]", w.Mode, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("%v: no changes reported", w.Mode)
+		}
+		w.Close()
+	}
+}
+
+func writeFile(t *testing.T, path string) {
+	err := os.WriteFile(path, []byte("changed"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bevane/repocheck/app"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+var branchesFormat string
+var mergedOnly bool
+var noUpstreamOnly bool
+var staleValue string
+
+// formats the branches can be output in
+var branchesFormats = []string{"table", "tsv", "json"}
+
+// branchesCmd lists the local branches of every repo
+var branchesCmd = &cobra.Command{
+	Use:   "branches [path]",
+	Short: "List the local branches of every repo",
+	Long: `List every local branch of each repo with its upstream branch, how many commits
+it is ahead and behind, the date and author of its last commit and whether it
+is merged into the default branch. The checked out branch is marked with *.
+
+The default branch is the branch that HEAD of the origin remote points to,
+otherwise main or master. A branch is merged when it is merged into the local
+or the origin default branch. The filter flags select the repos in the same way
+as they do for repocheck and --merged, --no-upstream and --stale select the
+branches of those repos.`,
+	Example: "repocheck branches ~/projects\nrepocheck branches --merged --stale 90d",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    listBranches,
+}
+
+func init() {
+	branchesCmd.Flags().StringVarP(&branchesFormat, "format", "f", "table", "Output format\noptions: "+strings.Join(branchesFormats, " | "))
+	branchesCmd.Flags().BoolVarP(&mergedOnly, "merged", "", false, "Only list branches that are merged into the default branch")
+	branchesCmd.Flags().BoolVarP(&noUpstreamOnly, "no-upstream", "", false, "Only list branches without an upstream branch")
+	branchesCmd.Flags().StringVarP(&staleValue, "stale", "", "", "Only list branches without commits for this long such as 90d, 2w or 36h")
+	branchesCmd.Flags().IntVarP(&jobs, "jobs", "", 4, "Number of repos to list the branches of at the same time")
+}
+
+func listBranches(cmd *cobra.Command, args []string) error {
+	branchesFormat = strings.ToLower(branchesFormat)
+	if !slices.Contains(branchesFormats, branchesFormat) {
+		return fmt.Errorf("repocheck: %v is not a valid format. Formats: %v", branchesFormat, strings.Join(branchesFormats, " | "))
+	}
+	if jobs < 1 {
+		return fmt.Errorf("repocheck: --jobs must be at least 1")
+	}
+	filter := app.BranchFilter{Merged: mergedOnly, NoUpstream: noUpstreamOnly}
+	if staleValue != "" {
+		age, err := app.ParseAge(staleValue)
+		if err != nil {
+			return fmt.Errorf("repocheck: %v", err)
+		}
+		filter.StaleBefore = time.Now().Add(-age)
+	}
+	err := app.ValidateDateFormat(dateFormat)
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	// errors from here on are not caused by the usage of the command
+	cmd.SilenceUsage = true
+	s := startSpinner()
+	_, repos, err := findRepos(args, !noFetch)
+	if err != nil {
+		s.Stop()
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	dates = app.NewDateFormat(dateFormat, location)
+	repoBranches := make([][]app.Branch, len(repos))
+	repoErrs := make([]error, len(repos))
+	app.ForEachRepo(repos, jobs, func(i int, repo app.Repo) {
+		repoBranches[i], repoErrs[i] = app.GetBranches(repo)
+	})
+	failed := 0
+	for i, err := range repoErrs {
+		if err != nil {
+			slog.Warn(fmt.Sprintf("Unable to list branches in %v, %v", repos[i].AbsPath, err))
+			failed++
+		}
+	}
+	s.Stop()
+	LogWriter.Flush()
+	var branches []app.Branch
+	for _, r := range repoBranches {
+		for _, branch := range r {
+			if filter.Match(branch) {
+				branches = append(branches, branch)
+			}
+		}
+	}
+	switch branchesFormat {
+	case "json":
+		fmt.Print(app.ConstructBranchJSONOutput(branches))
+	case "tsv":
+		fmt.Print(app.ConstructBranchTSVOutput(branches, dates))
+	default:
+		t, err := app.ConstructBranchTable(branches, dates)
+		if err != nil {
+			return fmt.Errorf("repocheck: error constructing table: %v", err)
+		}
+		fmt.Println(t)
+		fmt.Println(app.ConstructBranchSummary(branches, len(repos)))
+	}
+	if failed > 0 {
+		return fmt.Errorf("repocheck: unable to list the branches of %v repo(s)", failed)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bevane/repocheck/app"
+	"github.com/spf13/cobra"
+)
+
+// cloneCmd clones the repos in a manifest
+var cloneCmd = &cobra.Command{
+	Use:   "clone MANIFEST [dir]",
+	Short: "Clone the repos in a manifest written by export",
+	Long: `Clone each repo in a json manifest written by repocheck export into its path
+under dir, or the current directory if dir is not given.
+
+Each repo is cloned from its origin remote, or its first remote if there is no
+origin, and its recorded branch is checked out. Its other remotes are added
+after cloning. When the branch cannot be checked out the default branch of the
+remote is checked out instead and the repo is reported as default-branch. A
+clone that cannot be set up is removed again. Repos that already exist are
+left as they are and are reported as drift if their remote url differs from
+the manifest.`,
+	Example: "repocheck clone workspace.json ~/projects\nrepocheck clone --dry-run workspace.json",
+	Args:    cobra.RangeArgs(1, 2),
+	RunE:    cloneRepos,
+}
+
+func init() {
+	cloneCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "Show the repos that would be cloned without cloning them")
+	cloneCmd.Flags().IntVarP(&jobs, "jobs", "", 4, "Number of repos to clone at the same time")
+}
+
+func cloneRepos(cmd *cobra.Command, args []string) error {
+	if jobs < 1 {
+		return fmt.Errorf("repocheck: --jobs must be at least 1")
+	}
+	// errors from here on are not caused by the usage of the command
+	cmd.SilenceUsage = true
+	manifest, err := app.LoadManifest(args[0])
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	root, err := resolveRoot(args[1:])
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	s := startSpinner()
+	results := make([]app.Result, len(manifest.Repos))
+	app.ForEachRepo(manifest.Repos, jobs, func(i int, repo app.ManifestRepo) {
+		results[i] = app.CloneRepo(repo, root, dryRun)
+	})
+	s.Stop()
+	return printResults(results, app.CloneStatuses, app.CloneFailed)
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/bevane/repocheck/app"
 	"github.com/spf13/cobra"
 )
 
@@ -67,3 +68,29 @@ PowerShell:
 		return nil
 	},
 }
+
+// completes the names of the views defined in the config file
+func completeViewNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	configPath, err := app.ConfigPath()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	config, err := app.LoadConfig(configPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return config.ViewNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completes the view name for the first argument of the view command and
+// a file path for the second argument
+func completeViewArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return completeViewNames(cmd, args, toComplete)
+	case 1:
+		return nil, cobra.ShellCompDirectiveDefault
+	default:
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bevane/repocheck/app"
+	"github.com/spf13/cobra"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+var diffJSON bool
+var listSnapshots bool
+
+// diffCmd compares two snapshots saved with --save-snapshot
+var diffCmd = &cobra.Command{
+	Use:   "diff [from] [to]",
+	Short: "Show what changed between two snapshots",
+	Long: `Show the repos that were added or removed, became synced or not synced, changed
+branch or had activity between two snapshots saved with --save-snapshot.
+
+Each snapshot is given as its name, the path of a snapshot file or a date in
+the form yyyy-mm-dd, which selects the last snapshot taken by the end of that
+day. Without arguments the two most recent snapshots are compared and with one
+argument that snapshot is compared with the most recent snapshot.
+
+Snapshots are saved in repocheck/snapshots inside $XDG_DATA_HOME or
+~/.local/share on Linux and inside the user config directory on other
+platforms, or in the snapshots directory inside the REPOCHECK_DATA_DIR
+environment variable when it is set.`,
+	Example: "repocheck diff 2024-01-05\nrepocheck diff --list",
+	Args:    cobra.MaximumNArgs(2),
+	RunE:    diffSnapshots,
+}
+
+func init() {
+	diffCmd.Flags().BoolVarP(&diffJSON, "json", "j", false, "Output as json")
+	diffCmd.Flags().BoolVarP(&listSnapshots, "list", "", false, "List the saved snapshots")
+}
+
+func diffSnapshots(cmd *cobra.Command, args []string) error {
+	var err error
+	location, err = locationFromFlags()
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	// errors from here on are not caused by the usage of the command
+	cmd.SilenceUsage = true
+	dir, err := app.SnapshotDir()
+	if err != nil {
+		return fmt.Errorf("repocheck: error finding snapshot directory: %v", err)
+	}
+	names, err := app.ListSnapshots(dir)
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	if listSnapshots {
+		if len(names) > 0 {
+			fmt.Println(strings.Join(names, "\n"))
+		}
+		return nil
+	}
+	// the most recent snapshots are compared when they are not given
+	var paths []string
+	for i := len(names) - 1; i >= 0 && len(paths)+len(args) < 2; i-- {
+		paths = append([]string{filepath.Join(dir, names[i]+".json")}, paths...)
+	}
+	if len(paths)+len(args) < 2 {
+		return fmt.Errorf("repocheck: at least two snapshots are needed, save snapshots with --save-snapshot")
+	}
+	// dates are days in the time zone set with --tz or local time
+	loc := location
+	if loc == nil {
+		loc = time.Local
+	}
+	// the time of day is always shown since snapshots are often taken on the
+	// same day
+	dates = app.NewDateFormat("datetime", loc)
+	for i, arg := range args {
+		path, err := app.ResolveSnapshot(dir, arg, loc)
+		if err != nil {
+			return fmt.Errorf("repocheck: %v", err)
+		}
+		// the given snapshots take the place of the oldest snapshots
+		paths = slices.Insert(paths, i, path)
+	}
+	from, err := app.LoadSnapshot(paths[0])
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	to, err := app.LoadSnapshot(paths[1])
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	diff := app.DiffSnapshots(from, to, dates)
+	if diffJSON {
+		jsonOutput, _ := json.MarshalIndent(diff, "", "\t")
+		fmt.Println(string(jsonOutput))
+		return nil
+	}
+	fmt.Print(app.ConstructDiffOutput(diff, dates))
+	return nil
+}
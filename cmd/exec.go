@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/bevane/repocheck/app"
+	"github.com/spf13/cobra"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var execOutput string
+var failFast bool
+
+// ways the output of the commands can be shown
+var execOutputs = []string{"grouped", "prefixed"}
+
+// execCmd runs a command in each repo
+var execCmd = &cobra.Command{
+	Use:   "exec [path] -- COMMAND [ARG...]",
+	Short: "Run a command in each repo",
+	Long: `Run a command in the directory of each repo.
+
+The command is run directly without a shell, so paths with spaces are passed
+as they are. Use sh -c to run a shell command. The filter flags select the
+repos in the same way as they do for repocheck.
+
+The output of each repo is either shown together once the command is done in
+the repo (grouped) or line by line as it is written with the name of the repo
+at the start of each line (prefixed). A table with the exit status of the
+command in each repo is shown at the end.`,
+	Example: "repocheck exec ~/projects -- git status -s\nrepocheck exec --synced n --jobs 8 --output prefixed -- sh -c 'make test'",
+	Args: func(cmd *cobra.Command, args []string) error {
+		dash := cmd.ArgsLenAtDash()
+		if dash == -1 || dash == len(args) {
+			return fmt.Errorf("a command to run must be given after --")
+		}
+		if dash > 1 {
+			return fmt.Errorf("accepts at most 1 path before --, received %v", dash)
+		}
+		return nil
+	},
+	RunE: execInRepos,
+}
+
+func init() {
+	execCmd.Flags().IntVarP(&jobs, "jobs", "", 4, "Number of repos to run the command in at the same time")
+	execCmd.Flags().StringVarP(&execOutput, "output", "o", "grouped", "How the output of the command is shown\noptions: "+strings.Join(execOutputs, " | "))
+	execCmd.Flags().BoolVarP(&failFast, "fail-fast", "", false, "Do not run the command in more repos after it fails in a repo")
+}
+
+func execInRepos(cmd *cobra.Command, args []string) error {
+	if jobs < 1 {
+		return fmt.Errorf("repocheck: --jobs must be at least 1")
+	}
+	execOutput = strings.ToLower(execOutput)
+	if !slices.Contains(execOutputs, execOutput) {
+		return fmt.Errorf("repocheck: %v is not a valid output option. Options: %v", execOutput, strings.Join(execOutputs, " | "))
+	}
+	// errors from here on are not caused by the usage of the command
+	cmd.SilenceUsage = true
+	dash := cmd.ArgsLenAtDash()
+	command := args[dash:]
+	s := startSpinner()
+	_, repos, err := findRepos(args[:dash], !noFetch)
+	s.Stop()
+	LogWriter.Flush()
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	results := make([]app.Result, len(repos))
+	// serializes writes to stdout from the repos
+	var mu sync.Mutex
+	var failed atomic.Bool
+	app.ForEachRepo(repos, jobs, func(i int, repo app.Repo) {
+		if failFast && failed.Load() {
+			results[i] = app.Result{Repo: repo, Status: app.ExecSkipped, Detail: "an earlier repo failed"}
+			return
+		}
+		if execOutput == "prefixed" {
+			w := app.NewPrefixWriter(os.Stdout, "["+repo.Name+"] ", &mu)
+			results[i] = app.RunInRepo(repo, command, w)
+			w.Close()
+		} else {
+			var buf bytes.Buffer
+			results[i] = app.RunInRepo(repo, command, &buf)
+			mu.Lock()
+			fmt.Printf("==> %v (%v) <==\n%v\n", repo.Name, repo.AbsPath, buf.String())
+			mu.Unlock()
+		}
+		if results[i].Status == app.ExecFailed {
+			failed.Store(true)
+		}
+	})
+	return printResults(results, app.ExecStatuses, app.ExecFailed)
+}
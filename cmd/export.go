@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bevane/repocheck/app"
+	"github.com/spf13/cobra"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+var exportFormat string
+var exportFile string
+
+// formats a manifest can be exported in
+var exportFormats = []string{"json", "yaml", "mrconfig", "repo-xml"}
+
+// exportCmd writes a manifest of the repos
+var exportCmd = &cobra.Command{
+	Use:   "export [path]",
+	Short: "Write a manifest of the repos to clone them elsewhere",
+	Long: `Write a manifest of each repo with its path relative to the searched directory,
+its remotes, its current branch and the commit of HEAD.
+
+The json and yaml formats contain every field and the json manifest can be
+restored with repocheck clone. mrconfig writes a .mrconfig for myrepos and
+repo-xml writes a manifest for the repo tool. Repos without a remote cannot be
+cloned so they are only listed in a comment in the mrconfig and repo-xml
+formats. Passwords in remote urls are left out of every format.
+
+The repos are never fetched since the manifest only records what is on disk,
+so --synced compares each repo with its remote tracking branches as of the
+last fetch and --no-fetch has no effect.`,
+	Example: "repocheck export ~/projects -o workspace.json\nrepocheck export --format mrconfig ~/projects -o ~/projects/.mrconfig",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    exportRepos,
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "json", "Manifest format\noptions: "+strings.Join(exportFormats, " | "))
+	exportCmd.Flags().StringVarP(&exportFile, "output", "o", "", "File to write the manifest to instead of stdout")
+}
+
+func exportRepos(cmd *cobra.Command, args []string) error {
+	exportFormat = strings.ToLower(exportFormat)
+	if !slices.Contains(exportFormats, exportFormat) {
+		return fmt.Errorf("repocheck: %v is not a valid format. Formats: %v", exportFormat, strings.Join(exportFormats, " | "))
+	}
+	// errors from here on are not caused by the usage of the command
+	cmd.SilenceUsage = true
+	s := startSpinner()
+	_, repos, err := findRepos(args, false)
+	if err != nil {
+		s.Stop()
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	manifest, err := app.NewManifest(repos, time.Now().UTC().Truncate(time.Second))
+	s.Stop()
+	LogWriter.Flush()
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	var output string
+	switch exportFormat {
+	case "json":
+		output = app.ConstructManifestJSON(manifest)
+	case "yaml":
+		output = app.ConstructManifestYAML(manifest)
+	case "mrconfig":
+		output = app.ConstructMrconfig(manifest)
+	case "repo-xml":
+		output = app.ConstructRepoToolXML(manifest)
+	}
+	if exportFile == "" {
+		fmt.Print(output)
+		return nil
+	}
+	err = os.WriteFile(exportFile, []byte(output), 0644)
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bevane/repocheck/app"
+	"github.com/spf13/cobra"
+)
+
+// pullCmd fast-forwards the repos that are behind their upstream branch
+var pullCmd = &cobra.Command{
+	Use:   "pull [path]",
+	Short: "Fast-forward repos that are behind their upstream branch",
+	Long: `Fast-forward the checked out branch of each repo that is behind its upstream
+branch.
+
+A repo is only updated when it has no uncommitted changes and its branch is not
+also ahead of its upstream branch, so repocheck never merges or rebases. The
+filter flags select the repos in the same way as they do for repocheck.`,
+	Example: "repocheck pull --dry-run ~/projects\nrepocheck pull --author me",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    pullRepos,
+}
+
+func init() {
+	pullCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "Show the repos that would be updated without updating them")
+	pullCmd.Flags().IntVarP(&jobs, "jobs", "", 4, "Number of repos to pull at the same time")
+}
+
+func pullRepos(cmd *cobra.Command, args []string) error {
+	if jobs < 1 {
+		return fmt.Errorf("repocheck: --jobs must be at least 1")
+	}
+	// errors from here on are not caused by the usage of the command
+	cmd.SilenceUsage = true
+	s := startSpinner()
+	_, repos, err := findRepos(args, !noFetch)
+	if err != nil {
+		s.Stop()
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	results := make([]app.Result, len(repos))
+	app.ForEachRepo(repos, jobs, func(i int, repo app.Repo) {
+		results[i] = app.PullRepo(repo, dryRun)
+	})
+	s.Stop()
+	LogWriter.Flush()
+	return printResults(results, app.PullStatuses, app.PullFailed)
+}
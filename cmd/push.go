@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/bevane/repocheck/app"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+var setUpstream bool
+var assumeYes bool
+
+// pushCmd pushes the local branches that are ahead of their upstream branch
+var pushCmd = &cobra.Command{
+	Use:   "push [path]",
+	Short: "Push branches that are ahead of their upstream branch",
+	Long: `Push the local branches of each repo that are ahead of their upstream branch.
+
+Branches that are behind or have diverged from their upstream branch are not
+pushed. With --set-upstream, branches without an upstream branch are pushed to
+the origin remote and set as the upstream branch. The branches to push are
+shown and confirmation is asked for before pushing unless --yes is used. The
+filter flags select the repos in the same way as they do for repocheck.`,
+	Example: "repocheck push --dry-run ~/projects\nrepocheck push --set-upstream --yes",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    pushRepos,
+}
+
+func init() {
+	pushCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "Show the branches that would be pushed without pushing them")
+	pushCmd.Flags().BoolVarP(&setUpstream, "set-upstream", "u", false, "Push branches without an upstream branch and set the upstream branch")
+	pushCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Push without asking for confirmation")
+	pushCmd.Flags().IntVarP(&jobs, "jobs", "", 4, "Number of repos to push at the same time")
+}
+
+func pushRepos(cmd *cobra.Command, args []string) error {
+	if jobs < 1 {
+		return fmt.Errorf("repocheck: --jobs must be at least 1")
+	}
+	// errors from here on are not caused by the usage of the command
+	cmd.SilenceUsage = true
+	s := startSpinner()
+	_, repos, err := findRepos(args, !noFetch)
+	if err != nil {
+		s.Stop()
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	repoPlans := make([][]app.PushPlan, len(repos))
+	app.ForEachRepo(repos, jobs, func(i int, repo app.Repo) {
+		plans, err := app.PlanPush(repo, setUpstream)
+		if err != nil {
+			plans = []app.PushPlan{{Result: app.Result{Repo: repo, Status: app.PushFailed, Detail: err.Error()}}}
+		}
+		repoPlans[i] = plans
+	})
+	s.Stop()
+	LogWriter.Flush()
+	var planned []app.Result
+	toPush := 0
+	for _, plans := range repoPlans {
+		for _, plan := range plans {
+			planned = append(planned, plan.Result)
+			if plan.Status == app.PushWouldPush {
+				toPush++
+			}
+		}
+	}
+	if dryRun || toPush == 0 {
+		return printResults(planned, app.PushStatuses, app.PushFailed)
+	}
+	if !assumeYes {
+		t, err := app.ConstructResultTable(planned)
+		if err != nil {
+			return fmt.Errorf("repocheck: error constructing table: %v", err)
+		}
+		fmt.Println(t)
+		confirmed, err := confirm(fmt.Sprintf("Push %v branch(es)?", toPush))
+		if err != nil {
+			return fmt.Errorf("repocheck: %v", err)
+		}
+		if !confirmed {
+			fmt.Fprintln(os.Stderr, "push cancelled")
+			return nil
+		}
+	}
+	// push the branches of each repo one at a time since pushes to the same
+	// repo would compete for its lock files
+	repoResults := make([][]app.Result, len(repos))
+	app.ForEachRepo(repos, jobs, func(i int, repo app.Repo) {
+		for _, plan := range repoPlans[i] {
+			repoResults[i] = append(repoResults[i], app.Push(plan))
+		}
+	})
+	var results []app.Result
+	for _, r := range repoResults {
+		results = append(results, r...)
+	}
+	return printResults(results, app.PushStatuses, app.PushFailed)
+}
+
+// asks the user to confirm with y or yes on stdin
+func confirm(question string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%v [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, fmt.Errorf("error reading confirmation: %v", err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bevane/repocheck/app"
+	"github.com/briandowns/spinner"
+	"github.com/spf13/cobra"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+)
+
+// options for the subcommands that act on repos
+var dryRun bool
+var jobs int
+
+// shows a spinner on stderr until it is stopped
+func startSpinner() *spinner.Spinner {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(os.Stderr))
+	// the spinner needs to be stopped before exiting during a interrupt
+	// signal such as ctrl+c, otherwise the cursor will not be returned
+	// to the shell
+	go func() {
+		<-c
+		s.Stop()
+		os.Exit(130)
+	}()
+	s.Start()
+	return s
+}
+
+// flags of the root command that select repos. Subcommands that act on repos
+// share them so that they act on the same repos that are shown by repocheck
+var filterFlags = []string{"synced", "lastmodified", "author", "author-match", "tz", "no-fetch"}
+
+// adds the filter flags of the root command to cmd. The flags are shared so
+// that both commands set the same variables. Must be called after the flags of
+// the root command are defined
+func addFilterFlags(cmd *cobra.Command) {
+	addRootFlags(cmd, filterFlags)
+}
+
+// adds the flags of the root command called flagNames to cmd in the same way
+// as addFilterFlags
+func addRootFlags(cmd *cobra.Command, flagNames []string) {
+	for _, flagName := range flagNames {
+		cmd.Flags().AddFlag(rootCmd.Flags().Lookup(flagName))
+	}
+}
+
+// returns the absolute path of the directory to check, which is the path in
+// args or the current working directory if args is empty
+func resolveRoot(args []string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("error getting working dir: %v", err)
+	}
+	// if no arg is provided, run repocheck on current working directory
+	if len(args) == 0 {
+		return wd, nil
+	}
+	// support passing in path both as an absolute path and a
+	// relative path
+	pathArg := args[0]
+	if filepath.IsAbs(pathArg) {
+		return pathArg, nil
+	}
+	return filepath.Join(wd, pathArg), nil
+}
+
+// returns the time zone set with the tz flag or nil when it is not set
+func locationFromFlags() (*time.Location, error) {
+	if timeZone == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("%v is not a valid time zone", timeZone)
+	}
+	return loc, nil
+}
+
+// returns the directory to check and the repos in it that match the filter
+// flags. The repos are in the order they were found. Each repo is fetched
+// first when fetch is true
+func findRepos(args []string, fetch bool) (string, []app.Repo, error) {
+	var err error
+	location, err = locationFromFlags()
+	if err != nil {
+		return "", nil, err
+	}
+	queries := filterQueriesFromFlags()
+	err = queries.Validate()
+	if err != nil {
+		return "", nil, err
+	}
+	root, err := resolveRoot(args)
+	if err != nil {
+		return "", nil, err
+	}
+	repos, err := app.GetReposWithDetails(root, fetch)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot run check on '%v': %v", root, err)
+	}
+	err = queries.Apply(&repos)
+	if err != nil {
+		return "", nil, err
+	}
+	return root, repos, nil
+}
+
+// prints the table of results followed by the number of results with each
+// status. Returns an error if any result has the failed status so that the
+// exit code shows that not every repo succeeded
+func printResults(results []app.Result, statuses []string, failed string) error {
+	t, err := app.ConstructResultTable(results)
+	if err != nil {
+		return fmt.Errorf("repocheck: error constructing table: %v", err)
+	}
+	fmt.Printf("%v\n%v\n", t, app.ConstructResultSummary(results, statuses))
+	failures := 0
+	for _, result := range results {
+		if result.Status == failed {
+			failures++
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("repocheck: %v %v", failures, failed)
+	}
+	return nil
+}
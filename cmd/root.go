@@ -4,22 +4,65 @@ import (
 	"bufio"
 	"fmt"
 	"github.com/bevane/repocheck/app"
-	"github.com/briandowns/spinner"
+	"github.com/clinaresl/table"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
 	"log"
 	"os"
-	"os/signal"
-	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
 	"time"
 )
 
-var opt = app.NewQueries()
+var sortValue string
+var syncedValue string
+var lastModifiedValue string
+var authorValues []string
+var authorMatch string
 var tsvOutput bool
 var jsonOutput bool
+var csvOutput bool
+var outputFormat string
+var templateText string
+var templateFile string
+var templateList bool
+var outputTemplate *template.Template
 var noFetch bool
 var reverseSort bool
+var limit int
+var offset int
+var top string
+var groupBy string
+var columnNames []string
+var colorValue string
+var collapseSynced bool
+var showStats bool
+var saveSnapshot bool
+var dateFormat string
+var timeZone string
+
+// time zone set with the tz flag or nil when it is not set
+var location *time.Location
+
+// how dates are shown, set from the date-format and tz flags
+var dates app.DateFormat
 var LogWriter *bufio.Writer
 
+// flags that select the output format. Only one of them takes effect
+var outputFlags = []string{"format", "tsv", "json", "csv"}
+
+// formats accepted by the format flag
+var outputFormats = []string{"table", "tsv", "csv", "json", "ndjson", "markdown", "html", "template", "tree"}
+
+// options accepted by the color flag
+var colorOptions = []string{"auto", "always", "never"}
+
+// flags that need all the repos before output can be shown and so cannot be
+// used when streaming repos as they are found
+var nonStreamingFlags = []string{"sort", "reverse", "limit", "offset", "top", "group-by"}
+
 var rootCmd = &cobra.Command{
 	Use:   "repocheck [path]",
 	Short: "Repocheck is a cli tool that provides an overview of local git repos in a directory",
@@ -39,19 +82,70 @@ func init() {
 	// add completion command manually since the default sub command is
 	// disabled for cli's that dont have any other sub commands
 	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(viewCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(pullCmd)
+	rootCmd.AddCommand(pushCmd)
+	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(cloneCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(uiCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(branchesCmd)
 	// write logs to a buffer first and then flush buffer at the end to show
 	// the logs so that logs dont interrupt spinner which also outputs to
 	// stderr
 	LogWriter = bufio.NewWriter(os.Stderr)
 	log.SetOutput(LogWriter)
-	rootCmd.Flags().StringVarP(&opt.Sort.Value, "sort", "s", "lastmodified", "Sort results\noptions: author | lastmodified | name | path | synced")
-	rootCmd.Flags().StringVarP(&opt.Synced.Value, "synced", "S", "", "Filter by synced status of repo\noptions: y | n")
-	rootCmd.Flags().StringVarP(&opt.LastModified.Value, "lastmodified", "L", "", "Filter by last modified date of repo\noptions: yyyy-mm-dd | \">yyyy-mm-dd\" | \">=yyyy-mm-dd\"\nnote: surround any filters containing < or > with quotes")
-	rootCmd.Flags().StringVarP(&opt.Author.Value, "author", "A", "", "Filter by author of last commit")
+	rootCmd.Flags().StringVarP(&sortValue, "sort", "s", "lastmodified", "Sort results\noptions: "+strings.Join(app.SortOptions(), " | "))
+	rootCmd.Flags().StringVarP(&syncedValue, "synced", "S", "", "Filter by synced status of repo\noptions: y | n")
+	rootCmd.Flags().StringVarP(&lastModifiedValue, "lastmodified", "L", "", "Filter by last modified date of repo\noptions: yyyy-mm-dd | \">yyyy-mm-dd\" | \">=yyyy-mm-dd\"\nnote: surround any filters containing < or > with quotes")
+	rootCmd.Flags().StringArrayVarP(&authorValues, "author", "A", nil, "Filter by name or email of author of last commit\ncan be repeated to match any of the authors\n'me' matches the user in git config")
+	rootCmd.Flags().StringVarP(&authorMatch, "author-match", "", "substring", "How --author values are matched\noptions: substring | exact | regex")
 	rootCmd.Flags().BoolVarP(&reverseSort, "reverse", "r", false, "Sort the results in descending order")
+	rootCmd.Flags().IntVarP(&limit, "limit", "n", 0, "Show at most this many repos after sorting\n0 shows all repos")
+	rootCmd.Flags().IntVarP(&offset, "offset", "", 0, "Skip this many repos after sorting")
+	rootCmd.Flags().StringVarP(&top, "top", "", "", "Show the top repos for a preset, 10 repos unless --limit is set\noptions: busy | largest | stale")
+	rootCmd.Flags().StringVarP(&groupBy, "group-by", "g", "", "Show counts of repos for each group\noptions: "+strings.Join(app.GroupByOptions(), " | "))
+	rootCmd.Flags().StringSliceVarP(&columnNames, "columns", "c", nil, "Comma separated columns to show in the order given\noptions: "+strings.Join(app.ColumnOptions(), ", ")+"\ndefault: "+strings.Join(app.DefaultColumns, ","))
+	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", "table", "Output format\noptions: "+strings.Join(outputFormats, " | "))
+	rootCmd.Flags().StringVarP(&templateText, "template", "", "", "Go template executed for each repo when using --format template\nexample: '{{.Name}}\\t{{if not .SyncedWithRemote}}DIRTY{{end}}'")
+	rootCmd.Flags().StringVarP(&templateFile, "template-file", "", "", "File containing the template for --format template")
+	rootCmd.Flags().BoolVarP(&templateList, "template-list", "", false, "Execute the template once with .Repos, .Root and .Summary instead of once for each repo")
 	rootCmd.Flags().BoolVarP(&tsvOutput, "tsv", "t", false, "Output as tab separated values")
 	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output as json")
+	rootCmd.Flags().BoolVarP(&csvOutput, "csv", "", false, "Output as comma separated values")
+	rootCmd.Flags().BoolVarP(&showStats, "stats", "", false, "Show statistics such as counts of each sync problem and repos per author below the table\nthe json and ndjson outputs always include the statistics in the summary")
+	rootCmd.Flags().BoolVarP(&collapseSynced, "collapse-synced", "", false, "Hide the repos in directories where all repos are synced when using --format tree")
+	rootCmd.Flags().StringVarP(&colorValue, "color", "", "auto", "When to colour the table and the color function of templates, auto colours them when output is a terminal and NO_COLOR is not set\noptions: "+strings.Join(colorOptions, " | "))
+	rootCmd.Flags().StringVarP(&dateFormat, "date-format", "", "date", "How dates are shown\noptions: date | datetime | rfc3339 | relative | a go time layout such as \"02 Jan 2006\"")
+	rootCmd.Flags().StringVarP(&timeZone, "tz", "", "", "Time zone used to show dates and to compare dates in --lastmodified such as UTC or Europe/Berlin\ndefault: local time zone")
 	rootCmd.Flags().BoolVarP(&noFetch, "no-fetch", "", false, "Run without doing a git fetch for each repo")
+	rootCmd.Flags().BoolVarP(&saveSnapshot, "save-snapshot", "", false, "Save all the repos that are found, whether they match the filters or not, as a snapshot to compare with repocheck diff")
+	rootCmd.Flags().StringVarP(&viewName, "view", "", "", "Run with the flags saved in a view in the config file")
+	rootCmd.RegisterFlagCompletionFunc("view", completeViewNames)
+	addFilterFlags(pullCmd)
+	addFilterFlags(pushCmd)
+	addFilterFlags(execCmd)
+	addFilterFlags(exportCmd)
+	addFilterFlags(watchCmd)
+	addRootFlags(watchCmd, tableFlags)
+	addFilterFlags(uiCmd)
+	addRootFlags(uiCmd, uiFlags)
+	addRootFlags(serveCmd, []string{"no-fetch"})
+	addRootFlags(diffCmd, []string{"tz"})
+	addFilterFlags(branchesCmd)
+	addRootFlags(branchesCmd, []string{"date-format"})
+	// the view command accepts the same flags as the root command so that
+	// flags in the view can be overridden. The flags are shared so that both
+	// commands set the same variables
+	rootCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Name != "view" {
+			viewCmd.Flags().AddFlag(flag)
+		}
+	})
 }
 
 func Execute() {
@@ -62,46 +156,149 @@ func Execute() {
 }
 
 func repocheckCmd(cmd *cobra.Command, args []string) error {
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(os.Stderr))
-	// the spinner needs to be stopped before exiting during a interrupt
-	// signal such as ctrl+c, otherwise the cursor will not be returned
-	// to the shell
-	go func() {
-		<-c
-		s.Stop()
-		os.Exit(130)
-	}()
-	s.Start()
+	s := startSpinner()
 	var err error
 	var root string
+	// apply the view first so that the flag values from the view go through
+	// the same validation as flags set on the command line
+	if viewName != "" {
+		err = applyView(cmd, viewName)
+		if err != nil {
+			s.Stop()
+			return fmt.Errorf("repocheck: %v", err)
+		}
+	}
 	// run validation of flag values in the beginning before proceeding
 	// further to avoid unnecessary computation in the case of invalid
 	// flag values
-	err = app.ValidateQueries(opt)
+	err = app.ValidatePagination(offset, limit)
 	if err != nil {
 		s.Stop()
 		return fmt.Errorf("repocheck: %v", err)
 	}
-	wd, err := os.Getwd()
+	// a top preset is a shortcut for a sort and a limit so it replaces the
+	// sort flag value before the queries are validated
+	if top != "" {
+		if cmd.Flags().Changed("sort") {
+			s.Stop()
+			return fmt.Errorf("repocheck: --top cannot be used together with --sort")
+		}
+		err = app.ValidateTop(top)
+		if err != nil {
+			s.Stop()
+			return fmt.Errorf("repocheck: %v", err)
+		}
+		var reversePreset bool
+		sortValue, reversePreset = app.TopPreset(top)
+		// --reverse flips the order of the preset instead of being ignored
+		reverseSort = reverseSort != reversePreset
+		if !flagSet(cmd, "limit") {
+			limit = 10
+		}
+	}
+	// the output format shortcuts take precedence over the format flag
+	switch {
+	case tsvOutput:
+		outputFormat = "tsv"
+	case csvOutput:
+		outputFormat = "csv"
+	case jsonOutput:
+		outputFormat = "json"
+	}
+	outputFormat = strings.ToLower(outputFormat)
+	// a template implies the template format unless another format was chosen
+	if (templateText != "" || templateFile != "") && !flagSet(cmd, "format") && outputFormat == "table" {
+		outputFormat = "template"
+	}
+	if !slices.Contains(outputFormats, outputFormat) {
+		s.Stop()
+		return fmt.Errorf("repocheck: %v is not a valid format. Options: %v", outputFormat, strings.Join(outputFormats, " | "))
+	}
+	colorValue = strings.ToLower(colorValue)
+	if !slices.Contains(colorOptions, colorValue) {
+		s.Stop()
+		return fmt.Errorf("repocheck: %v is not a valid color option. Options: %v", colorValue, strings.Join(colorOptions, " | "))
+	}
+	err = app.ValidateDateFormat(dateFormat)
 	if err != nil {
 		s.Stop()
-		return fmt.Errorf("repocheck: error getting working dir: %v", err)
+		return fmt.Errorf("repocheck: %v", err)
 	}
-	// if no arg is provided, run repocheck on current working directory
-	if len(args) == 0 {
-		root = wd
-	} else {
-		// support passing in path both as an absolute path and a
-		// relative path
-		pathArg := args[0]
-		if filepath.IsAbs(pathArg) {
-			root = pathArg
-		} else {
-			root = filepath.Join(wd, pathArg)
+	location, err = locationFromFlags()
+	if err != nil {
+		s.Stop()
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	dates = app.NewDateFormat(dateFormat, location)
+	if outputFormat == "ndjson" {
+		for _, flagName := range nonStreamingFlags {
+			if cmd.Flags().Changed(flagName) {
+				s.Stop()
+				return fmt.Errorf("repocheck: --format ndjson outputs repos as they are found and cannot be used with --%v", flagName)
+			}
 		}
 	}
+	if outputFormat == "template" {
+		outputTemplate, err = templateFromFlags()
+		if err != nil {
+			s.Stop()
+			return fmt.Errorf("repocheck: %v", err)
+		}
+	}
+	if showStats && !slices.Contains([]string{"table", "json", "ndjson"}, outputFormat) {
+		s.Stop()
+		return fmt.Errorf("repocheck: --stats does not support the %v format", outputFormat)
+	}
+	if collapseSynced && outputFormat != "tree" {
+		s.Stop()
+		return fmt.Errorf("repocheck: --collapse-synced can only be used with --format tree")
+	}
+	if flagSet(cmd, "columns") {
+		err = app.ValidateColumns(columnNames)
+		if err != nil {
+			s.Stop()
+			return fmt.Errorf("repocheck: %v", err)
+		}
+	}
+	if groupBy != "" {
+		err = app.ValidateGroupBy(groupBy)
+		if err != nil {
+			s.Stop()
+			return fmt.Errorf("repocheck: %v", err)
+		}
+		if !slices.Contains([]string{"table", "tsv", "csv", "json"}, outputFormat) {
+			s.Stop()
+			return fmt.Errorf("repocheck: --group-by does not support the %v format", outputFormat)
+		}
+		// groups count every repo that matched so that they agree with the
+		// summary, which leaves nothing for pagination to apply to
+		if limit > 0 || offset > 0 {
+			s.Stop()
+			return fmt.Errorf("repocheck: --group-by counts all the repos that match the filters and cannot be used with --limit, --offset or --top")
+		}
+	}
+	queries := queriesFromFlags()
+	err = queries.Validate()
+	if err != nil {
+		s.Stop()
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	root, err = resolveRoot(args)
+	if err != nil {
+		s.Stop()
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	if outputFormat == "ndjson" {
+		// stop the spinner before any output since repos are output while
+		// the spinner would otherwise still be running
+		s.Stop()
+		allRepos, err := streamNDJSON(root, queries)
+		LogWriter.Flush()
+		if err != nil {
+			return fmt.Errorf("repocheck: %v", err)
+		}
+		return saveSnapshotOf(allRepos, root)
+	}
 	repos, err := app.GetReposWithDetails(root, !noFetch)
 	if err != nil {
 		s.Stop()
@@ -111,36 +308,225 @@ func repocheckCmd(cmd *cobra.Command, args []string) error {
 			err,
 		)
 	}
+	// the snapshot has every repo that was found since queries filter in
+	// place
+	allRepos := slices.Clone(repos)
 	// applies all queries that have been set through flags
-	err = app.ApplyQueries(opt, &repos)
+	err = queries.Apply(&repos)
 	if err != nil {
 		s.Stop()
 		return fmt.Errorf("repocheck: %v", err)
 	}
-	// apply reverse sort at the end if reverse sort flag is true
-	if reverseSort {
-		app.ReverseSort(&repos)
-	}
+	// keep all the matched repos for the summary before limiting the repos
+	// that will be shown
+	matchedRepos := repos
+	app.Paginate(&repos, offset, limit)
 	var output string
-	switch {
-	case tsvOutput:
-		output = app.ConstructTSVOutput(repos)
-	case jsonOutput:
-		output = app.ConstructJSONOutput(repos)
-	default:
-		table, err := app.ConstructTable(repos)
-		if err != nil {
-			s.Stop()
-			return fmt.Errorf(
-				"repocheck: error constructing table: %v",
-				err,
-			)
-		}
-		summary := app.ConstructSummary(repos, root)
-		output = fmt.Sprintf("%v\n%v\n", table, summary)
+	if groupBy != "" {
+		output, err = constructGroupOutput(matchedRepos, root)
+	} else {
+		output, err = constructOutput(repos, matchedRepos, root)
+	}
+	if err != nil {
+		s.Stop()
+		return fmt.Errorf("repocheck: %v", err)
 	}
 	s.Stop()
 	LogWriter.Flush()
 	fmt.Print(output)
+	return saveSnapshotOf(allRepos, root)
+}
+
+// returns the repos in the output format selected by the output flags.
+// matchedRepos are all the repos that matched the queries before pagination
+func constructOutput(repos []app.Repo, matchedRepos []app.Repo, root string) (string, error) {
+	switch outputFormat {
+	case "tsv":
+		return app.ConstructTSVOutput(repos, columnNames, dates), nil
+	case "csv":
+		return app.ConstructCSVOutput(repos, columnNames, dates), nil
+	case "json":
+		return app.ConstructJSONOutput(jsonOutputFor(repos, matchedRepos, root), columnNames), nil
+	case "markdown":
+		summary := app.ConstructSummary(matchedRepos, len(repos), root)
+		return app.ConstructMarkdownOutput(repos, columnNames, summary, dates), nil
+	case "template":
+		summary := app.ConstructSummary(matchedRepos, len(repos), root)
+		return app.ConstructTemplateOutput(outputTemplate, repos, root, summary, templateList)
+	case "tree":
+		summary := app.ConstructSummary(matchedRepos, len(repos), root)
+		return app.ConstructTreeOutput(repos, root, collapseSynced, summary), nil
+	case "html":
+		summary := app.ConstructSummary(matchedRepos, len(repos), root)
+		output, err := app.ConstructHTMLOutput(repos, columnNames, summary, dates)
+		if err != nil {
+			return "", fmt.Errorf("error constructing html report: %v", err)
+		}
+		return output, nil
+	default:
+		var t *table.Table
+		var err error
+		if style, ok := tableStyleFromFlags(); ok {
+			t, err = app.ConstructStyledTable(repos, columnNames, style, dates)
+		} else {
+			t, err = app.ConstructTable(repos, columnNames, dates)
+		}
+		if err != nil {
+			return "", fmt.Errorf("error constructing table: %v", err)
+		}
+		summary := app.ConstructSummary(matchedRepos, len(repos), root)
+		return fmt.Sprintf("%v\n%v\n", t, summary) + statsFor(matchedRepos, root), nil
+	}
+}
+
+// returns the style for the table and whether the table should be styled. The
+// plain table is used when stdout is not a terminal unless colour is forced so
+// that the output does not change when it is piped to other programs
+func tableStyleFromFlags() (app.TableStyle, bool) {
+	fd := int(os.Stdout.Fd())
+	isTerminal := term.IsTerminal(fd)
+	style := app.TableStyle{Color: colorFromFlags()}
+	if isTerminal {
+		width, _, err := term.GetSize(fd)
+		if err == nil {
+			style.Width = width
+		}
+	}
+	return style, isTerminal || style.Color
+}
+
+// returns whether to colour the output as set with the color flag. auto
+// colours the output when stdout is a terminal and NO_COLOR is not set
+func colorFromFlags() bool {
+	switch colorValue {
+	case "always":
+		return true
+	case "auto":
+		return term.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("NO_COLOR") == ""
+	}
+	return false
+}
+
+// returns the stats block shown below the summary when the stats flag is set
+func statsFor(matchedRepos []app.Repo, root string) string {
+	if !showStats {
+		return ""
+	}
+	return "\n" + app.ConstructStats(app.Summarize(matchedRepos).Stats, dates)
+}
+
+func jsonOutputFor(repos []app.Repo, matchedRepos []app.Repo, root string) app.Output {
+	generatedAt := time.Now().UTC().Truncate(time.Second)
+	return app.NewOutput(repos, root, app.Summarize(matchedRepos), generatedAt)
+}
+
+// saves allRepos as a snapshot when --save-snapshot is set. allRepos are all
+// the repos that were found before the filters were applied so that diff
+// compares the same repos whatever filters each snapshot was taken with
+func saveSnapshotOf(allRepos []app.Repo, root string) error {
+	if !saveSnapshot {
+		return nil
+	}
+	dir, err := app.SnapshotDir()
+	if err != nil {
+		return fmt.Errorf("repocheck: error finding snapshot directory: %v", err)
+	}
+	_, err = app.SaveSnapshot(dir, jsonOutputFor(allRepos, allRepos, root))
+	if err != nil {
+		return fmt.Errorf("repocheck: cannot save snapshot: %v", err)
+	}
 	return nil
 }
+
+// writes each repo that matches the queries to stdout as soon as its details
+// have been gathered, followed by the summary. Returns all the repos that were
+// found, including the repos that did not match
+func streamNDJSON(root string, queries *app.Registry) ([]app.Repo, error) {
+	writer := app.NewNDJSONWriter(os.Stdout, columnNames)
+	var matchedRepos []app.Repo
+	var writeErr error
+	allRepos, err := app.GetReposWithDetailsFunc(root, !noFetch, func(repo app.Repo) {
+		if writeErr != nil || !queries.Match(repo) {
+			return
+		}
+		matchedRepos = append(matchedRepos, repo)
+		writeErr = writer.WriteRepo(repo)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot run check on '%v': %v", root, err)
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+	return allRepos, writer.WriteSummary(root, app.Summarize(matchedRepos))
+}
+
+// parses the template from the template or template file flag
+func templateFromFlags() (*template.Template, error) {
+	switch {
+	case templateText != "" && templateFile != "":
+		return nil, fmt.Errorf("--template and --template-file cannot be used together")
+	case templateText != "":
+		// the shell does not interpret escapes such as \n in the template
+		return app.ParseTemplate(templateText, app.TemplateOptions{Unescape: true, Color: colorFromFlags()})
+	case templateFile != "":
+		text, err := os.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading template file: %v", err)
+		}
+		return app.ParseTemplate(string(text), app.TemplateOptions{Color: colorFromFlags()})
+	default:
+		return nil, fmt.Errorf("--format template requires --template or --template-file")
+	}
+}
+
+// returns the counts for each group of repos in the output format selected
+// by the output flags
+func constructGroupOutput(matchedRepos []app.Repo, root string) (string, error) {
+	groups := app.GroupRepos(matchedRepos, groupBy)
+	switch outputFormat {
+	case "tsv":
+		return app.ConstructGroupTSVOutput(groups, dates), nil
+	case "csv":
+		return app.ConstructGroupCSVOutput(groups, dates), nil
+	case "json":
+		return app.ConstructGroupJSONOutput(jsonOutputFor(nil, matchedRepos, root), groups, columnNames), nil
+	default:
+		table, err := app.ConstructGroupTable(groups, dates)
+		if err != nil {
+			return "", fmt.Errorf("error constructing table: %v", err)
+		}
+		summary := app.ConstructSummary(matchedRepos, len(matchedRepos), root)
+		return fmt.Sprintf("%v\n%v\n", table, summary) + statsFor(matchedRepos, root), nil
+	}
+}
+
+// returns a registry with a query for each filter flag that was used followed
+// by the sort and the reverse sort
+func queriesFromFlags() *app.Registry {
+	queries := filterQueriesFromFlags()
+	// place sort at the end as there will be less elements to sort after
+	// filtering
+	queries.Add(app.NewSorter(sortValue))
+	if reverseSort {
+		queries.Add(app.NewReverser())
+	}
+	return queries
+}
+
+// returns a registry with a query for each filter flag that was used
+func filterQueriesFromFlags() *app.Registry {
+	queries := app.NewRegistry()
+	// ignore filters where the value has not been set indicating that the
+	// flag for the filter was not used
+	if lastModifiedValue != "" {
+		queries.Add(app.NewLastModifiedFilterIn(lastModifiedValue, location))
+	}
+	if syncedValue != "" {
+		queries.Add(app.NewSyncedFilter(syncedValue))
+	}
+	if len(authorValues) > 0 {
+		queries.Add(app.NewAuthorFilter(authorMatch, authorValues...))
+	}
+	return queries
+}
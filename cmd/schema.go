@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bevane/repocheck/app"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd prints the json schema of the json output
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the JSON output",
+	Long: fmt.Sprintf(`Print the JSON Schema of the output of --format json.
+
+The output includes a schemaVersion, which is currently %v. The version is only
+incremented when a field is removed or changes meaning. New fields can be added
+without changing the version, so consumers should ignore fields they do not
+know.`, app.SchemaVersion),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Print(app.JSONSchema())
+	},
+}
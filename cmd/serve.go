@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bevane/repocheck/app"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+var serveAddr string
+var scanInterval time.Duration
+
+// serveCmd serves the repos over http
+var serveCmd = &cobra.Command{
+	Use:   "serve [path]",
+	Short: "Serve the repos as a JSON API and a web dashboard",
+	Long: `Serve the repos over http as a JSON API and a web dashboard.
+
+The repos and their branches, uncommitted changes and recent commits are found
+in the background every --interval and kept between requests, so requests
+never run git. Endpoints:
+
+  GET  /                 dashboard
+  GET  /api/repos        the JSON output of repocheck --format json
+  GET  /api/repos/{path} a repo with its branches, uncommitted changes and
+                         recent commits as of the last scan. path is relative
+                         to the served directory
+  POST /api/refresh      find the repos again without waiting for --interval
+
+/api/repos accepts the query parameters synced, lastmodified, author (can be
+repeated), author-match, tz, sort, reverse, limit, offset and columns with the
+same values as the flags of the same name, such as /api/repos?synced=n&sort=name.
+
+The server only listens on localhost by default. Use --addr :8080 to make it
+reachable from other machines, which shows the paths and authors of the repos
+to anyone that can reach it.`,
+	Example: "repocheck serve ~/projects\nrepocheck serve --addr :8080 --interval 10m ~/projects",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    serve,
+}
+
+func init() {
+	serveCmd.Flags().StringVarP(&serveAddr, "addr", "", "localhost:8080", "Address to listen on")
+	serveCmd.Flags().DurationVarP(&scanInterval, "interval", "", 5*time.Minute, "How often to find the repos again")
+}
+
+func serve(cmd *cobra.Command, args []string) error {
+	if scanInterval <= 0 {
+		return fmt.Errorf("repocheck: --interval must be greater than 0")
+	}
+	root, err := resolveRoot(args)
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	// errors from here on are not caused by the usage of the command
+	cmd.SilenceUsage = true
+	// the server runs until it is stopped so logs are shown as they happen
+	// instead of being buffered until the end
+	log.SetOutput(os.Stderr)
+	server := app.NewServer(root, !noFetch)
+	go server.Run(scanInterval, nil)
+	log.Printf("serving repos in %v on http://%v", root, serveAddr)
+	err = http.ListenAndServe(serveAddr, server.Handler())
+	return fmt.Errorf("repocheck: %v", err)
+}
@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/bevane/repocheck/app"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"time"
+)
+
+// escape sequences to switch to the alternate screen of the terminal, which
+// keeps the scrollback intact, and to hide the cursor
+const (
+	enterUIScreen = "\033[?1049h\033[?25l"
+	leaveUIScreen = "\033[?25h\033[?1049l"
+)
+
+// messages shown while the slow actions run
+var actionProgress = map[string]string{
+	app.UIActionFetch:   "fetching...",
+	app.UIActionPull:    "pulling...",
+	app.UIActionRefresh: "searching for repos...",
+}
+
+// flags of the root command that also apply to the ui
+var uiFlags = []string{"sort", "reverse", "date-format"}
+
+// uiCmd shows the repos in an interactive terminal ui
+var uiCmd = &cobra.Command{
+	Use:   "ui [path]",
+	Short: "Browse and act on repos in an interactive terminal ui",
+	Long: `Show the repos in a full screen terminal ui that updates as the repos change.
+
+The selected repo is shown with its branches, uncommitted changes and recent
+commits. Type / to filter the repos by name, path or author as you type.
+
+Keys:
+  ↑ ↓ k j        move the selection
+  pgup pgdown    move by a page
+  / esc          filter, clear the filter
+  f              fetch the selected repo
+  p              fast-forward the selected repo like repocheck pull
+  s              open a shell in the selected repo
+  y              copy the path of the selected repo to the clipboard
+  r              search for repos again
+  q              quit
+
+The path is copied with an OSC 52 escape sequence, which works over ssh and in
+tmux with set-clipboard on but is not supported by every terminal.`,
+	Example: "repocheck ui ~/projects\nrepocheck ui --synced n",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    runUI,
+}
+
+func init() {
+	uiCmd.Flags().DurationVarP(&pollInterval, "poll-interval", "", 2*time.Second, "How often to check the repos for changes when file system events are not used")
+	uiCmd.Flags().BoolVarP(&forcePolling, "poll", "", false, "Check the repos for changes every --poll-interval instead of using file system events")
+}
+
+func runUI(cmd *cobra.Command, args []string) error {
+	stdin, stdout := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !term.IsTerminal(stdin) || !term.IsTerminal(stdout) {
+		return fmt.Errorf("repocheck: ui needs a terminal")
+	}
+	if pollInterval <= 0 {
+		return fmt.Errorf("repocheck: --poll-interval must be greater than 0")
+	}
+	err := app.ValidateDateFormat(dateFormat)
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	location, err = locationFromFlags()
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	dates = app.NewDateFormat(dateFormat, location)
+	queries := queriesFromFlags()
+	err = queries.Validate()
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	root, err := resolveRoot(args)
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	// errors from here on are not caused by the usage of the command
+	cmd.SilenceUsage = true
+	// warnings would be drawn over the ui
+	log.SetOutput(io.Discard)
+	s := startSpinner()
+	repos, err := app.GetReposWithDetails(root, !noFetch)
+	s.Stop()
+	if err != nil {
+		return fmt.Errorf("repocheck: cannot run check on '%v': %v", root, err)
+	}
+
+	state, err := term.MakeRaw(stdin)
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	fmt.Print(enterUIScreen)
+	defer func() {
+		fmt.Print(leaveUIScreen)
+		term.Restore(stdin, state)
+	}()
+
+	r := &uiRunner{
+		ui:      app.NewUI(nil, root, dates),
+		root:    root,
+		queries: queries,
+		repos:   repos,
+		infos:   map[string]app.RepoInfo{},
+	}
+	r.ui.SetRepos(r.matching())
+	watcher := app.NewWatcher(repos, pollInterval, forcePolling)
+	defer func() { watcher.Close() }()
+
+	// keys are only read after the previous keys were handled so that no
+	// keys are taken from the shell opened by an action
+	keys := make(chan []byte)
+	readNext := make(chan bool)
+	go func() {
+		buf := make([]byte, 64)
+		for range readNext {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- slices.Clone(buf[:n])
+		}
+	}()
+	readNext <- true
+	defer close(readNext)
+	// the size of the terminal is checked regularly since there is no
+	// portable way to be told that it changed
+	resize := time.NewTicker(250 * time.Millisecond)
+	defer resize.Stop()
+	width, height := 0, 0
+	// returns whether the size changed since it was last checked
+	checkSize := func() bool {
+		w, h, err := term.GetSize(stdout)
+		if err != nil || (w == width && h == height) {
+			return false
+		}
+		width, height = w, h
+		// clear what was drawn for the old size
+		fmt.Print("\033[2J")
+		return true
+	}
+	checkSize()
+	// whether anything changed since the ui was last drawn
+	changed := true
+	for {
+		if changed {
+			r.draw(width, height)
+		}
+		changed = true
+		select {
+		case b, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, key := range app.ParseKeys(b) {
+				action := r.ui.HandleKey(key)
+				if action == app.UIActionQuit {
+					return nil
+				}
+				if action == app.UIActionNone {
+					continue
+				}
+				// show that a slow action is running before running it
+				if progress, ok := actionProgress[action]; ok {
+					r.ui.Message = progress
+					r.draw(width, height)
+				}
+				if action == app.UIActionRefresh {
+					watcher.Close()
+					r.run(action, stdin, state)
+					watcher = app.NewWatcher(r.repos, pollInterval, forcePolling)
+					continue
+				}
+				r.run(action, stdin, state)
+			}
+			readNext <- true
+		case paths := <-watcher.Changes:
+			r.update(refreshRepos(r.repos, root, paths), paths)
+		case <-resize.C:
+			changed = checkSize()
+		}
+	}
+}
+
+// runs the ui and the actions on the repos
+type uiRunner struct {
+	ui   *app.UI
+	root string
+	// the queries of the filter and sort flags, which are validated before
+	// the ui is started
+	queries *app.Registry
+	// all the repos before filtering
+	repos []app.Repo
+	// info for the repos that have been selected, by path
+	infos map[string]app.RepoInfo
+}
+
+// returns the repos that match the filter flags in the order of the sort flags
+func (r *uiRunner) matching() []app.Repo {
+	matched := slices.Clone(r.repos)
+	r.queries.Apply(&matched)
+	return matched
+}
+
+// replaces the repos and forgets the info of the repos at paths
+func (r *uiRunner) update(repos []app.Repo, paths []string) {
+	r.repos = repos
+	for _, path := range paths {
+		delete(r.infos, path)
+	}
+	r.ui.SetRepos(r.matching())
+}
+
+func (r *uiRunner) draw(width int, height int) {
+	var info *app.RepoInfo
+	if repo, ok := r.ui.Selected(); ok {
+		if _, ok := r.infos[repo.Path]; !ok {
+			repoInfo, err := app.GetRepoInfo(repo)
+			if err != nil {
+				r.ui.Message = fmt.Sprintf("%v: %v", repo.Name, err)
+			}
+			r.infos[repo.Path] = repoInfo
+		}
+		repoInfo := r.infos[repo.Path]
+		info = &repoInfo
+	}
+	fmt.Print("\033[H" + r.ui.Render(width, height, info))
+}
+
+// runs action on the selected repo and sets the message of the ui to the
+// outcome
+func (r *uiRunner) run(action string, stdin int, state *term.State) {
+	if action == app.UIActionRefresh {
+		repos, err := app.GetReposWithDetails(r.root, false)
+		if err != nil {
+			r.ui.Message = err.Error()
+			return
+		}
+		r.infos = map[string]app.RepoInfo{}
+		r.repos = repos
+		r.ui.SetRepos(r.matching())
+		r.ui.Message = fmt.Sprintf("found %v repos", len(repos))
+		return
+	}
+	repo, ok := r.ui.Selected()
+	if !ok {
+		return
+	}
+	switch action {
+	case app.UIActionFetch:
+		refreshed, err := app.GetRepoDetails(r.root, repo.Path, true)
+		if err != nil {
+			r.ui.Message = fmt.Sprintf("%v: %v", repo.Name, err)
+			return
+		}
+		r.replace(refreshed)
+		r.ui.Message = "fetched " + repo.Name
+		if refreshed.FetchFailed {
+			r.ui.Message = "fetch failed in " + repo.Name
+		}
+	case app.UIActionPull:
+		result := app.PullRepo(repo, false)
+		r.ui.Message = fmt.Sprintf("%v: %v", repo.Name, result.Status)
+		if result.Detail != "" {
+			r.ui.Message += ", " + result.Detail
+		}
+		r.refresh(repo)
+	case app.UIActionShell:
+		fmt.Print(leaveUIScreen)
+		term.Restore(stdin, state)
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			shell = "sh"
+			if runtime.GOOS == "windows" {
+				shell = "cmd"
+			}
+		}
+		fmt.Printf("repocheck: opening %v in %v, exit the shell to return\n", shell, repo.AbsPath)
+		c := exec.Command(shell)
+		c.Dir = repo.AbsPath
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		err := c.Run()
+		term.MakeRaw(stdin)
+		fmt.Print(enterUIScreen + "\033[2J")
+		r.ui.Message = "returned from " + shell
+		if err != nil {
+			r.ui.Message += ": " + err.Error()
+		}
+		r.refresh(repo)
+	case app.UIActionCopy:
+		// OSC 52 asks the terminal to set the clipboard
+		fmt.Printf("\033]52;c;%v\a", base64.StdEncoding.EncodeToString([]byte(repo.AbsPath)))
+		r.ui.Message = "copied " + repo.AbsPath
+	}
+}
+
+// gathers the details of repo again without fetching
+func (r *uiRunner) refresh(repo app.Repo) {
+	refreshed, err := app.GetRepoDetails(r.root, repo.Path, false)
+	if err != nil {
+		return
+	}
+	refreshed.FetchFailed = repo.FetchFailed
+	r.replace(refreshed)
+}
+
+func (r *uiRunner) replace(repo app.Repo) {
+	i := slices.IndexFunc(r.repos, func(other app.Repo) bool { return other.Path == repo.Path })
+	if i == -1 {
+		return
+	}
+	repos := slices.Clone(r.repos)
+	repos[i] = repo
+	r.update(repos, []string{repo.Path})
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bevane/repocheck/app"
+	"github.com/spf13/cobra"
+	"slices"
+)
+
+var viewName string
+
+// viewCmd runs repocheck with the flags saved in a named view
+var viewCmd = &cobra.Command{
+	Use:   "view NAME [path]",
+	Short: "Run repocheck with a saved view",
+	Long: `Run repocheck with the flags saved in a named view.
+
+Views are defined in the config file, which is located at
+$XDG_CONFIG_HOME/repocheck/config.json on Linux or the path set in the
+REPOCHECK_CONFIG environment variable. Each view maps flag names to values:
+
+  {
+    "views": {
+      "mine-dirty": {"author": "Foo Bar", "synced": "n", "tsv": true}
+    }
+  }
+
+Flags passed on the command line take precedence over the values in the view.`,
+	Example:           "repocheck view mine-dirty ~/projects",
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeViewArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		viewName = args[0]
+		return repocheckCmd(cmd, args[1:])
+	},
+}
+
+// flags that were set by the view that was applied. They are not marked as
+// changed so that the checks for flags that cannot be used together only
+// consider the flags given on the command line
+var viewFlags = map[string]bool{}
+
+// returns whether the flag was given on the command line or set by the view
+func flagSet(cmd *cobra.Command, flagName string) bool {
+	return cmd.Flags().Changed(flagName) || viewFlags[flagName]
+}
+
+// sets the flags saved in the view to the values in the view, skipping flags
+// that were explicitly set by the user
+func applyView(cmd *cobra.Command, name string) error {
+	configPath, err := app.ConfigPath()
+	if err != nil {
+		return fmt.Errorf("error finding config file: %v", err)
+	}
+	config, err := app.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	view, err := config.View(name)
+	if err != nil {
+		return err
+	}
+	flagValues, err := view.FlagValues()
+	if err != nil {
+		return fmt.Errorf("view %v: %v", name, err)
+	}
+	// set the flags in a fixed order so that errors are deterministic
+	var flagNames []string
+	for flagName := range flagValues {
+		flagNames = append(flagNames, flagName)
+	}
+	slices.Sort(flagNames)
+	// an output format chosen on the command line replaces the output format
+	// of the view instead of competing with it
+	outputFlagChanged := slices.ContainsFunc(outputFlags, func(flagName string) bool {
+		return cmd.Flags().Changed(flagName)
+	})
+	for _, flagName := range flagNames {
+		if flagName == "view" {
+			return fmt.Errorf("view %v: a view cannot use another view", name)
+		}
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil {
+			return fmt.Errorf("view %v: unknown flag %v", name, flagName)
+		}
+		if flag.Changed || (outputFlagChanged && slices.Contains(outputFlags, flagName)) {
+			continue
+		}
+		for _, value := range flagValues[flagName] {
+			// set the value without marking the flag as changed
+			err = flag.Value.Set(value)
+			if err != nil {
+				return fmt.Errorf("view %v: invalid value for %v: %v", name, flagName, err)
+			}
+		}
+		viewFlags[flagName] = true
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bevane/repocheck/app"
+	"github.com/spf13/cobra"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+var fetchInterval time.Duration
+var pollInterval time.Duration
+var forcePolling bool
+
+// flags of the root command that change how the table looks. watch shares
+// them so that the table looks the same as the table of repocheck
+var tableFlags = []string{"sort", "reverse", "columns", "color", "date-format", "stats"}
+
+// clears the terminal and moves the cursor to the top left
+const clearScreen = "\033[H\033[2J"
+
+// watchCmd keeps the table on screen and updates it when repos change
+var watchCmd = &cobra.Command{
+	Use:   "watch [path]",
+	Short: "Keep the table on screen and update it when repos change",
+	Long: `Keep the table of repos on screen and update it as soon as the files or the
+branches of a repo change.
+
+Only the repos that changed are checked again. Every --fetch-interval all the
+repos are fetched and the directory is searched again for new repos. Changes
+are found with file system events on Linux. When events are not available,
+such as on other platforms or when there are more directories than
+fs.inotify.max_user_watches allows, the repos are checked for changes every
+--poll-interval instead. The filter flags select the repos in the same way as
+they do for repocheck, so a repo shows up as soon as it matches.`,
+	Example: "repocheck watch --synced n ~/projects\nrepocheck watch --fetch-interval 1m --columns name,branch,syncdetails",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    watchRepos,
+}
+
+func init() {
+	watchCmd.Flags().DurationVarP(&fetchInterval, "fetch-interval", "", 5*time.Minute, "How often to fetch the repos and search for new repos\n0 never searches again")
+	watchCmd.Flags().DurationVarP(&pollInterval, "poll-interval", "", 2*time.Second, "How often to check the repos for changes when file system events are not used")
+	watchCmd.Flags().BoolVarP(&forcePolling, "poll", "", false, "Check the repos for changes every --poll-interval instead of using file system events")
+}
+
+func watchRepos(cmd *cobra.Command, args []string) error {
+	colorValue = strings.ToLower(colorValue)
+	if !slices.Contains(colorOptions, colorValue) {
+		return fmt.Errorf("repocheck: %v is not a valid color option. Options: %v", colorValue, strings.Join(colorOptions, " | "))
+	}
+	err := app.ValidateDateFormat(dateFormat)
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	if cmd.Flags().Changed("columns") {
+		err = app.ValidateColumns(columnNames)
+		if err != nil {
+			return fmt.Errorf("repocheck: %v", err)
+		}
+	}
+	if pollInterval <= 0 {
+		return fmt.Errorf("repocheck: --poll-interval must be greater than 0")
+	}
+	if fetchInterval < 0 {
+		return fmt.Errorf("repocheck: --fetch-interval cannot be negative")
+	}
+	location, err = locationFromFlags()
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	dates = app.NewDateFormat(dateFormat, location)
+	queries := queriesFromFlags()
+	err = queries.Validate()
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	root, err := resolveRoot(args)
+	if err != nil {
+		return fmt.Errorf("repocheck: %v", err)
+	}
+	// errors from here on are not caused by the usage of the command
+	cmd.SilenceUsage = true
+	// warnings would be cleared along with the screen straight away
+	log.SetOutput(io.Discard)
+	// ctrl+c exits through the interrupt handler of the spinner
+	s := startSpinner()
+	repos, err := app.GetReposWithDetails(root, !noFetch)
+	s.Stop()
+	if err != nil {
+		return fmt.Errorf("repocheck: cannot run check on '%v': %v", root, err)
+	}
+	watcher := app.NewWatcher(repos, pollInterval, forcePolling)
+	defer func() { watcher.Close() }()
+	var rescan <-chan time.Time
+	if fetchInterval > 0 {
+		ticker := time.NewTicker(fetchInterval)
+		defer ticker.Stop()
+		rescan = ticker.C
+	}
+	for {
+		output, err := watchOutput(repos, root, watcher.Mode, queries)
+		if err != nil {
+			return fmt.Errorf("repocheck: %v", err)
+		}
+		fmt.Print(clearScreen + output)
+		select {
+		case paths := <-watcher.Changes:
+			repos = refreshRepos(repos, root, paths)
+		case <-rescan:
+			watcher.Close()
+			repos, err = app.GetReposWithDetails(root, !noFetch)
+			if err != nil {
+				return fmt.Errorf("repocheck: cannot run check on '%v': %v", root, err)
+			}
+			watcher = app.NewWatcher(repos, pollInterval, forcePolling)
+		}
+	}
+}
+
+// returns repos with the details of the repos at paths gathered again without
+// fetching. Repos that are no longer valid repos are removed
+func refreshRepos(repos []app.Repo, root string, paths []string) []app.Repo {
+	refreshed := slices.Clone(repos)
+	for _, path := range paths {
+		i := slices.IndexFunc(refreshed, func(repo app.Repo) bool { return repo.Path == path })
+		if i == -1 {
+			continue
+		}
+		repo, err := app.GetRepoDetails(root, path, false)
+		if err != nil {
+			refreshed = slices.Delete(refreshed, i, i+1)
+			continue
+		}
+		// the result of the last fetch still applies
+		repo.FetchFailed = refreshed[i].FetchFailed
+		refreshed[i] = repo
+	}
+	return refreshed
+}
+
+// returns the table of the repos that match queries followed by when it was
+// updated. queries must be validated first
+func watchOutput(repos []app.Repo, root string, mode string, queries *app.Registry) (string, error) {
+	// queries filter and sort in place so they are applied to a copy
+	matchedRepos := slices.Clone(repos)
+	err := queries.Apply(&matchedRepos)
+	if err != nil {
+		return "", err
+	}
+	output, err := constructOutput(matchedRepos, matchedRepos, root)
+	if err != nil {
+		return "", err
+	}
+	updated := time.Now()
+	if location != nil {
+		updated = updated.In(location)
+	}
+	return output + fmt.Sprintf("\nWatching %v repos using %v, updated at %v\n", len(repos), mode, updated.Format(time.TimeOnly)), nil
+}
@@ -2,12 +2,18 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"github.com/bevane/repocheck/app"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 // temp location to store the fake repos
@@ -53,6 +59,458 @@ func TestRepoCheckNoFlags(t *testing.T) {
 
 }
 
+func TestRepoCheckTop(t *testing.T) {
+	// repo c is the most recently modified repo so it should be the only
+	// repo shown when using the busy preset with a limit of 1
+	cmd := exec.Command("./repocheck", root, "--top", "busy", "--limit", "1", "--tsv")
+	out, _ := cmd.Output()
+	got := string(out)
+	want := "Name\tPath\tAuthor\tLastModified\tSynced\tSyncDetails\n" +
+		"c\t/tmp/repochecktest/local/c\tTest Author C\t2024-01-03\tfalse\tuntracked branch(es), branch(es) ahead\n"
+	if got != want {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestRepoCheckView(t *testing.T) {
+	configPath := filepath.Join(root, "config.json")
+	config := `{"views": {"unsynced": {"synced": "n", "sort": "name", "tsv": true}}}`
+	err := os.WriteFile(configPath, []byte(config), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Name\tPath\tAuthor\tLastModified\tSynced\tSyncDetails\n" +
+		"b\t/tmp/repochecktest/local/b\tTest Author B\t2024-01-02\tfalse\tuncommitted changes\n" +
+		"c\t/tmp/repochecktest/local/c\tTest Author C\t2024-01-03\tfalse\tuntracked branch(es), branch(es) ahead\n"
+	// the view can be used either as a flag or as a subcommand
+	for _, args := range [][]string{
+		{root, "--view", "unsynced", "--no-fetch"},
+		{"view", "unsynced", root, "--no-fetch"},
+		// the output format of the view is replaced by the output format
+		// from the command line
+		{"view", "unsynced", root, "--no-fetch", "--csv", "-c", "name"},
+	} {
+		cmd := exec.Command("./repocheck", args...)
+		cmd.Env = append(os.Environ(), "REPOCHECK_CONFIG="+configPath)
+		out, _ := cmd.Output()
+		got := string(out)
+		if slices.Contains(args, "--csv") {
+			if got != "Name\nb\nc\n" {
+				t.Errorf("%v\ngot:\n%v\nwant:\n%v", args, got, "Name\nb\nc\n")
+			}
+			continue
+		}
+		if got != want {
+			t.Errorf("%v\ngot:\n%v\nwant:\n%v", args, got, want)
+		}
+	}
+	// flags from the view do not conflict with flags from the command line
+	for _, args := range [][]string{
+		{"view", "unsynced", root, "--no-fetch", "--top", "busy"},
+		{"view", "unsynced", root, "--no-fetch", "--format", "ndjson"},
+	} {
+		cmd := exec.Command("./repocheck", args...)
+		cmd.Env = append(os.Environ(), "REPOCHECK_CONFIG="+configPath)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Errorf("%v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestRepoCheckAuthor(t *testing.T) {
+	// authors can be matched by email and multiple authors are combined
+	// with OR
+	cmd := exec.Command(
+		"./repocheck", root, "--no-fetch", "--tsv", "--sort", "name",
+		"-A", "testa@test.com", "-A", "test author b", "--author-match", "exact",
+	)
+	out, _ := cmd.Output()
+	got := string(out)
+	want := "Name\tPath\tAuthor\tLastModified\tSynced\tSyncDetails\n" +
+		"a\t/tmp/repochecktest/local/a\tTest Author A\t2024-01-01\ttrue\t\n" +
+		"b\t/tmp/repochecktest/local/b\tTest Author B\t2024-01-02\tfalse\tuncommitted changes\n"
+	if got != want {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestRepoCheckWatchAuthorRegex(t *testing.T) {
+	// regex patterns are compiled when the queries are validated so the
+	// table must be filtered with the validated queries
+	cmd := exec.Command(
+		"./repocheck", "watch", root, "--no-fetch", "--poll", "--fetch-interval", "0",
+		"-A", "^Test Author [AB]$", "--author-match", "regex",
+	)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmd.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+	// watch runs until it is stopped so stop it if the table never shows up
+	timer := time.AfterFunc(10*time.Second, func() { cmd.Process.Kill() })
+	defer timer.Stop()
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasSuffix(line, "repo(s) are not synced") {
+			want := "2 repos found in /tmp/repochecktest: 1 repo(s) are not synced"
+			if line != want {
+				t.Errorf("got:\n%v\nwant:\n%v", line, want)
+			}
+			return
+		}
+	}
+	t.Fatal("watch exited without showing the table")
+}
+
+func TestRepoCheckGroupBy(t *testing.T) {
+	cmd := exec.Command("./repocheck", root, "--no-fetch", "--tsv", "--group-by", "synced")
+	out, _ := cmd.Output()
+	got := string(out)
+	want := "Group\tTotal\tUnsynced\tLastActivity\n" +
+		"not synced\t2\t2\t2024-01-03\n" +
+		"synced\t1\t0\t2024-01-01\n"
+	if got != want {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+	// groups count all the matched repos so a page of repos cannot be grouped
+	err := exec.Command("./repocheck", root, "--no-fetch", "--group-by", "synced", "--limit", "1").Run()
+	if err == nil {
+		t.Errorf("--group-by with --limit succeeded, want error")
+	}
+}
+
+func TestRepoCheckColumns(t *testing.T) {
+	cmd := exec.Command("./repocheck", root, "--no-fetch", "--tsv", "--sort", "name", "--columns", "name,authoremail,synced")
+	out, _ := cmd.Output()
+	got := string(out)
+	want := "Name\tAuthorEmail\tSynced\n" +
+		"a\ttesta@test.com\ttrue\n" +
+		"b\ttestb@test.com\tfalse\n" +
+		"c\ttestc@test.com\tfalse\n"
+	if got != want {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestRepoCheckCSV(t *testing.T) {
+	cmd := exec.Command("./repocheck", root, "--no-fetch", "--csv", "--sort", "name", "--columns", "name,author,syncdetails")
+	out, _ := cmd.Output()
+	got := string(out)
+	want := "Name,Author,SyncDetails\n" +
+		"a,Test Author A,\n" +
+		"b,Test Author B,uncommitted changes\n" +
+		"c,Test Author C,\"untracked branch(es), branch(es) ahead\"\n"
+	if got != want {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestRepoCheckMarkdown(t *testing.T) {
+	cmd := exec.Command("./repocheck", root, "--no-fetch", "--format", "markdown", "--sort", "name", "--columns", "name,synced,syncdetails")
+	out, _ := cmd.Output()
+	got := string(out)
+	want := `| Repo | Synced | Sync Details |
+| --- | --- | --- |
+| a | true |  |
+| b | false | - uncommitted changes |
+| c | false | - untracked branch(es)<br>- branch(es) ahead |
+
+3 repos found in /tmp/repochecktest: 2 repo(s) are not synced
+`
+	if got != want {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestRepoCheckTemplate(t *testing.T) {
+	cmd := exec.Command(
+		"./repocheck", root, "--no-fetch", "--sort", "name", "--format", "template",
+		"--template", `{{.Name}}\t{{if not .SyncedWithRemote}}DIRTY{{end}}`,
+	)
+	out, _ := cmd.Output()
+	got := string(out)
+	want := "a\t\nb\tDIRTY\nc\tDIRTY\n"
+	if got != want {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestRepoCheckJSON(t *testing.T) {
+	cmd := exec.Command("./repocheck", root, "--no-fetch", "--json", "--sort", "name", "-n", "1", "--columns", "name")
+	out, _ := cmd.Output()
+	var got app.Output
+	err := json.Unmarshal(out, &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SchemaVersion != app.SchemaVersion || got.Root != root || got.GeneratedAt.IsZero() {
+		t.Errorf("unexpected envelope: %+v", got)
+	}
+	if len(got.Repos) != 1 || got.Repos[0].Name != "a" || got.Summary.Total != 3 {
+		t.Errorf("unexpected repos: %+v", got)
+	}
+}
+
+func TestRepoCheckSchema(t *testing.T) {
+	cmd := exec.Command("./repocheck", "schema")
+	out, _ := cmd.Output()
+	if string(out) != app.JSONSchema() {
+		t.Errorf("got:\n%v\nwant:\n%v", string(out), app.JSONSchema())
+	}
+}
+
+func TestRepoCheckNDJSON(t *testing.T) {
+	cmd := exec.Command("./repocheck", root, "--no-fetch", "--format", "ndjson", "--synced", "n", "--columns", "name,synced")
+	// the times in the summary are shown in the local time zone
+	cmd.Env = append(os.Environ(), "TZ=UTC")
+	out, _ := cmd.Output()
+	lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	// repos are output in the order they are found, which is not
+	// deterministic, but the summary is always last
+	slices.Sort(lines[:len(lines)-1])
+	got := strings.Join(lines, "\n")
+	want := `{"name":"b","synced":false}
+{"name":"c","synced":false}
+{"schemaVersion":1,"root":"/tmp/repochecktest","summary":{"total":2,"unsynced":2,"stats":{"syncProblems":{"branch(es) ahead":1,"uncommitted changes":1,"untracked branch(es)":1},"authors":{"Test Author B":1,"Test Author C":1},"oldestActivity":"2024-01-02T10:00:00Z","newestActivity":"2024-01-03T10:00:00Z","unpushedCommits":0,"uncommittedFiles":1,"withoutRemote":0,"fetchFailures":0}}}`
+	if got != want {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestRepoCheckPullDryRun(t *testing.T) {
+	cmd := exec.Command("./repocheck", "pull", root, "--no-fetch", "--dry-run")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	got := lines[len(lines)-1]
+	want := "1 up-to-date, 1 skipped-dirty, 1 no-upstream"
+	if got != want {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestRepoCheckPushDryRun(t *testing.T) {
+	var tests = []struct {
+		args []string
+		want string
+	}{
+		{[]string{}, "1 would-push, 2 up-to-date, 1 no-upstream"},
+		{[]string{"--set-upstream"}, "2 would-push, 2 up-to-date"},
+	}
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			args := append([]string{"push", root, "--no-fetch", "--dry-run"}, test.args...)
+			out, err := exec.Command("./repocheck", args...).Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+			got := lines[len(lines)-1]
+			if got != test.want {
+				t.Errorf("got:\n%v\nwant:\n%v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRepoCheckExec(t *testing.T) {
+	var tests = []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--", "git", "status"}, "3 ok"},
+		{[]string{"--synced", "n", "--", "sh", "-c", "exit 1"}, "2 failed"},
+		{[]string{"--jobs", "1", "--fail-fast", "--", "sh", "-c", "exit 1"}, "1 failed, 2 skipped"},
+	}
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			args := append([]string{"exec", root, "--no-fetch"}, test.args...)
+			out, _ := exec.Command("./repocheck", args...).Output()
+			lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+			got := lines[len(lines)-1]
+			if got != test.want {
+				t.Errorf("got:\n%v\nwant:\n%v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRepoCheckExport(t *testing.T) {
+	out, err := exec.Command("./repocheck", "export", filepath.Join(root, "local"), "--no-fetch").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest app.Manifest
+	err = json.Unmarshal(out, &manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, repo := range manifest.Repos {
+		got = append(got, repo.Path+" "+repo.Branch+" "+repo.Remotes[0].URL)
+	}
+	// a and b are on the default branch of git init
+	branch := manifest.Repos[0].Branch
+	want := []string{
+		"a " + branch + " " + filepath.Join(root, "remote", "a"),
+		"b " + branch + " " + filepath.Join(root, "remote", "b"),
+		"c newbranch " + filepath.Join(root, "remote", "c"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestRepoCheckClone(t *testing.T) {
+	out, err := exec.Command("./repocheck", "export", filepath.Join(root, "local"), "--no-fetch").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest app.Manifest
+	err = json.Unmarshal(out, &manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// clone over file:// so that git uses the same transport as for a
+	// remote on another machine
+	for _, repo := range manifest.Repos {
+		repo.Remotes[0].URL = "file://" + repo.Remotes[0].URL
+	}
+	// a second origin remote cannot be added after cloning so cloning the
+	// extra repo fails
+	manifest.Repos = append(manifest.Repos, app.ManifestRepo{
+		Path:    "extra",
+		Remotes: []app.Remote{manifest.Repos[0].Remotes[0], {Name: "origin", URL: "file:///nonexistent"}},
+	})
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "manifest.json")
+	err = os.WriteFile(manifestPath, []byte(app.ConstructManifestJSON(manifest)), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// newbranch of c and its commit were never pushed so the default branch
+	// is checked out instead. The failed clone of extra is removed so it is
+	// tried again instead of being reported as existing
+	var tests = []string{"2 cloned, 1 default-branch, 1 failed", "3 exists, 1 failed"}
+	for _, want := range tests {
+		out, _ := exec.Command("./repocheck", "clone", manifestPath, filepath.Join(dir, "workspace")).Output()
+		lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+		got := lines[len(lines)-1]
+		if got != want {
+			t.Errorf("got:\n%v\nwant:\n%v", got, want)
+		}
+	}
+	cmd := exec.Command("git", "branch", "--show-current")
+	cmd.Dir = filepath.Join(dir, "workspace", "a")
+	out, err = cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != manifest.Repos[0].Branch {
+		t.Errorf("got branch %v, want %v", got, manifest.Repos[0].Branch)
+	}
+}
+
+func TestRepoCheckDiff(t *testing.T) {
+	// the snapshots are most likely taken within the same second and must
+	// not replace each other
+	dataDir := t.TempDir()
+	env := append(os.Environ(), "REPOCHECK_DATA_DIR="+dataDir)
+	for _, args := range [][]string{{"--synced", "y"}, {}} {
+		cmd := exec.Command("./repocheck", append([]string{filepath.Join(root, "local"), "--no-fetch", "--save-snapshot"}, args...)...)
+		cmd.Env = env
+		err := cmd.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command("./repocheck", "diff", "--list")
+	cmd.Env = env
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := strings.Fields(string(out))
+	if len(names) != 2 {
+		t.Fatalf("got snapshots %v, want 2", names)
+	}
+	paths := []string{filepath.Join(dataDir, "snapshots", names[0]+".json")}
+	// without arguments the two most recent snapshots are compared
+	cmd = exec.Command("./repocheck", "diff", "--json")
+	cmd.Env = env
+	out, err = cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var diff app.SnapshotDiff
+	err = json.Unmarshal(out, &diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the snapshots have every repo whatever the filters so nothing changed
+	// between them
+	snapshot, err := app.LoadSnapshot(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Repos) != 3 {
+		t.Errorf("got %v repos in the snapshot taken with --synced y, want 3", len(snapshot.Repos))
+	}
+	changes := len(diff.Added) + len(diff.Removed) + len(diff.NewlyUnsynced) + len(diff.NewlySynced) + len(diff.Branch) + len(diff.Activity)
+	if changes != 0 {
+		t.Errorf("got %v changes, want none: %+v", changes, diff)
+	}
+}
+
+func TestRepoCheckBranches(t *testing.T) {
+	var tests = []struct {
+		flags []string
+		want  []string
+	}{
+		// c has a commit on its default branch that was not pushed and
+		// newbranch was created from that commit without an upstream
+		{nil, []string{"a default 0 0", "b default 0 0", "c default 1 0", "c newbranch merged 0 0"}},
+		{[]string{"--no-upstream"}, []string{"c newbranch merged 0 0"}},
+		{[]string{"--merged"}, []string{"c newbranch merged 0 0"}},
+		{[]string{"--stale", "90d"}, nil},
+	}
+	for _, test := range tests {
+		args := append([]string{"branches", filepath.Join(root, "local"), "--no-fetch", "-f", "json"}, test.flags...)
+		out, err := exec.Command("./repocheck", args...).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var branches []app.Branch
+		err = json.Unmarshal(out, &branches)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, branch := range branches {
+			name := branch.Name
+			if name == branch.DefaultBranch {
+				name = "default"
+			}
+			if branch.Merged {
+				name += " merged"
+			}
+			got = append(got, fmt.Sprintf("%v %v %v %v", branch.RepoName, name, branch.Ahead, branch.Behind))
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%v: got:\n%v\nwant:\n%v", test.flags, got, test.want)
+		}
+	}
+}
+
 func setup(root string) error {
 	var err error
 	err = initFakeRepos(root)
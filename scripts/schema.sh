@@ -0,0 +1,4 @@
+#!/bin/sh
+# generates the json schema of the json output
+set -e
+go run main.go schema >docs/repocheck.schema.json